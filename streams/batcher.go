@@ -2,45 +2,204 @@ package streams
 
 import (
 	"context"
+	"sync"
 	"time"
 )
 
 var _ Iterator[[]int] = &Batcher[int]{}
 
+// Clock is used to measure time.
+// It can be replaced in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// BatcherConfig configures a Batcher
+type BatcherConfig struct {
+	// Min is the number of elements which will cause a batch to be emitted without waiting.
+	Min int
+	// Max is the maximum number of elements in a batch.
+	// If Max < Min, then Min is used.
+	Max int
+	// Delay is the longest that a partial batch will be held, measured from when its first element was read.
+	Delay time.Duration
+	// Clock is used for timing. If it is nil, then the system clock is used.
+	Clock Clock
+}
+
+// Batcher groups the elements of an Iterator into batches.
+// The inner Iterator is read on a background goroutine, so a partial batch
+// will be emitted on time even if the inner Iterator is blocked.
+//
+// Close must be called to release the background goroutine.
 type Batcher[T any] struct {
 	inner Iterator[T]
-	min   int
-	dur   time.Duration
+	cfg   BatcherConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+	wg     sync.WaitGroup
+	items  chan batcherItem[T]
+
+	pending []T
+	start   time.Time
+	err     error
 }
 
+type batcherItem[T any] struct {
+	x   T
+	at  time.Time
+	err error
+}
+
+// NewBatcher creates a Batcher which emits a batch once it has min elements,
+// or once dur has passed since the first element in the batch.
+//
+// The first call to Next starts a goroutine which reads from inner until it returns EOS.
+// It does not use the context passed to Next, so Close must be called to cancel it and release the goroutine.
+// Errors other than EOS are returned from Next once, after any partial batch, and the Batcher can be retried.
 func NewBatcher[T any](inner Iterator[T], min int, dur time.Duration) *Batcher[T] {
+	return NewBatcherWithConfig(inner, BatcherConfig{Min: min, Delay: dur})
+}
+
+// NewBatcherWithConfig creates a Batcher using cfg.
+// See NewBatcher for the lifecycle of the background goroutine.
+func NewBatcherWithConfig[T any](inner Iterator[T], cfg BatcherConfig) *Batcher[T] {
+	if cfg.Min < 1 {
+		cfg.Min = 1
+	}
+	if cfg.Max < cfg.Min {
+		cfg.Max = cfg.Min
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = systemClock{}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Batcher[T]{
-		inner: inner,
-		min:   min,
-		dur:   dur,
+		inner:  inner,
+		cfg:    cfg,
+		ctx:    ctx,
+		cancel: cancel,
+		items:  make(chan batcherItem[T], cfg.Max),
 	}
 }
 
 func (b *Batcher[T]) Next(ctx context.Context, dst [][]T) (int, error) {
-	dst2 := &dst[0]
-	*dst2 = (*dst2)[:0]
-	start := time.Now()
-	// TODO: need context to cancel long running calls to inner.Next
-	for {
-		var x T
-		if err := NextUnit(ctx, b.inner, &x); err != nil {
-			if IsEOS(err) && len(*dst2) > 0 {
-				return 1, nil
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	b.once.Do(func() {
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			b.readLoop()
+		}()
+	})
+	if len(b.pending) == 0 {
+		if err := b.err; err != nil {
+			// only EOS is sticky, other errors are returned once.
+			if !IsEOS(err) {
+				b.err = nil
 			}
 			return 0, err
 		}
-		*dst2 = append(*dst2, x)
-		if len(*dst2) >= b.min {
-			break
+		// wait for the first element of the batch
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case item := <-b.items:
+			if item.err != nil {
+				if IsEOS(item.err) {
+					b.err = item.err
+				}
+				return 0, item.err
+			}
+			b.pending = append(b.pending, item.x)
+			b.start = item.at
 		}
-		if now := time.Now(); now.Sub(start) >= b.dur {
-			break
+	}
+	if b.err == nil {
+		if err := b.fill(ctx); err != nil {
+			return 0, err
 		}
 	}
+	dst[0] = append(dst[0][:0], b.pending...)
+	b.pending = b.pending[:0]
 	return 1, nil
 }
+
+// fill adds elements to pending until there are cfg.Min of them, the deadline passes, or the inner Iterator errors.
+// Then it adds any elements which are already available, up to cfg.Max.
+// An error from the inner Iterator is stored in err, to be returned after the partial batch.
+func (b *Batcher[T]) fill(ctx context.Context) error {
+	deadline := b.cfg.Clock.After(b.cfg.Delay - b.cfg.Clock.Now().Sub(b.start))
+	for len(b.pending) < b.cfg.Min {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return b.drain()
+		case item := <-b.items:
+			if item.err != nil {
+				b.err = item.err
+				return nil
+			}
+			b.pending = append(b.pending, item.x)
+		}
+	}
+	return b.drain()
+}
+
+// drain adds elements which are already available to pending, up to cfg.Max.
+func (b *Batcher[T]) drain() error {
+	for len(b.pending) < b.cfg.Max {
+		select {
+		case item := <-b.items:
+			if item.err != nil {
+				b.err = item.err
+				return nil
+			}
+			b.pending = append(b.pending, item.x)
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// readLoop reads from the inner Iterator until it returns EOS, or the Batcher is closed.
+// Other errors are passed to the consumer, and then reading continues.
+func (b *Batcher[T]) readLoop() {
+	for {
+		var item batcherItem[T]
+		item.err = NextUnit(b.ctx, b.inner, &item.x)
+		item.at = b.cfg.Clock.Now()
+		select {
+		case <-b.ctx.Done():
+			return
+		case b.items <- item:
+		}
+		if IsEOS(item.err) {
+			return
+		}
+	}
+}
+
+// Close stops the background goroutine, waits for it to exit, and then closes the inner Iterator.
+func (b *Batcher[T]) Close() error {
+	b.cancel()
+	b.wg.Wait()
+	return Close(b.inner)
+}
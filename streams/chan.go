@@ -1,6 +1,9 @@
 package streams
 
-import "context"
+import (
+	"context"
+	"errors"
+)
 
 // LoadChan loads a channel from an Iterator.
 // If the context is cancelled, LoadChan returns that error.
@@ -23,6 +26,32 @@ func LoadChan[T any](ctx context.Context, it Iterator[T], out chan<- T) error {
 	return nil
 }
 
+// ToChan reads from it on a new goroutine, and sends the elements to the returned channel.
+// The channel is closed when it is exhausted, it returns an error, or stop is called.
+//
+// stop must be called to release the goroutine, it can be called before the channel is closed to stop early.
+// stop returns the error which ended the stream, or nil if the stream ended with EOS, or was ended by stop.
+func ToChan[T any](ctx context.Context, it Iterator[T], bufSize int) (_ <-chan T, stop func() error) {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	out := make(chan T, bufSize)
+	done := make(chan struct{})
+	var err error
+	go func() {
+		defer close(done)
+		defer close(out)
+		err = LoadChan(ctx, it, out)
+	}()
+	return out, func() error {
+		cancel()
+		<-done
+		if errors.Is(err, context.Canceled) && parent.Err() == nil {
+			return nil
+		}
+		return err
+	}
+}
+
 var _ Iterator[int] = make(Chan[int])
 
 // Chan implements a stream backed by a channel
@@ -32,10 +61,14 @@ func (c Chan[T]) Next(ctx context.Context, dst []T) (int, error) {
 	if len(dst) == 0 {
 		return 0, nil
 	}
-	var ok bool
-	dst[0], ok = <-c
-	if !ok {
-		return 0, EOS()
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case x, ok := <-c:
+		if !ok {
+			return 0, EOS()
+		}
+		dst[0] = x
+		return 1, nil
 	}
-	return 1, nil
 }
@@ -0,0 +1,145 @@
+package streams
+
+import (
+	"context"
+	"fmt"
+
+	"go.brendoncarroll.net/exp/maybe"
+	"go.brendoncarroll.net/exp/sbe"
+)
+
+// Checkpointer is implemented by Iterators which can report their position,
+// so that iteration can be resumed later with Resume.
+type Checkpointer[T any] interface {
+	// Checkpoint returns the position of the Iterator, after the last element emitted by Next.
+	Checkpoint(ctx context.Context) (Checkpoint[T], error)
+}
+
+// Checkpoint is an opaque position in a stream.
+// It can be serialized with Marshal and ParseCheckpoint.
+type Checkpoint[T any] struct {
+	// key is passed to Seek, if it is set.
+	key maybe.Maybe[T]
+	// skip is the number of elements to skip after seeking.
+	skip uint64
+	// end is true if the stream had ended.
+	end bool
+}
+
+// NewCheckpoint returns a Checkpoint which is resumed by seeking to key, if it is set, and then skipping skip elements.
+// Iterators outside this package can use it to implement Checkpointer.
+func NewCheckpoint[T any](key maybe.Maybe[T], skip uint64) Checkpoint[T] {
+	return Checkpoint[T]{key: key, skip: skip}
+}
+
+// EndCheckpoint returns a Checkpoint for a stream which has ended.
+func EndCheckpoint[T any]() Checkpoint[T] {
+	return Checkpoint[T]{end: true}
+}
+
+const (
+	checkpointKey = 1 << iota
+	checkpointEnd
+)
+
+// Marshal appends the encoding of the Checkpoint to out.
+// marshal is used to encode the element that the Checkpoint may contain.
+func (c Checkpoint[T]) Marshal(out []byte, marshal func(out []byte, x T) []byte) []byte {
+	var flags byte
+	if c.key.Ok {
+		flags |= checkpointKey
+	}
+	if c.end {
+		flags |= checkpointEnd
+	}
+	out = append(out, flags)
+	out = sbe.AppendUVarint(out, c.skip)
+	if c.key.Ok {
+		out = marshal(out, c.key.X)
+	}
+	return out
+}
+
+// ParseCheckpoint parses a Checkpoint which was encoded with Checkpoint.Marshal.
+func ParseCheckpoint[T any](data []byte, unmarshal func(data []byte, dst *T) error) (Checkpoint[T], error) {
+	var c Checkpoint[T]
+	if len(data) < 1 {
+		return c, fmt.Errorf("streams: checkpoint is too short")
+	}
+	flags := data[0]
+	if flags&^(checkpointKey|checkpointEnd) != 0 {
+		return c, fmt.Errorf("streams: checkpoint has unknown flags %x", flags)
+	}
+	c.end = flags&checkpointEnd != 0
+	skip, rest, err := sbe.ReadUVarint(data[1:])
+	if err != nil {
+		return c, err
+	}
+	c.skip = skip
+	if flags&checkpointKey != 0 {
+		if err := unmarshal(rest, &c.key.X); err != nil {
+			return c, err
+		}
+		c.key.Ok = true
+	} else if len(rest) > 0 {
+		return c, fmt.Errorf("streams: checkpoint has %d trailing bytes", len(rest))
+	}
+	return c, nil
+}
+
+// Resume moves it to the position in c, and returns an Iterator which emits the rest of the stream.
+// it must be a new Iterator, created the same way as the Iterator which produced c, over the same data.
+// If c has a key, then it must implement Seeker.
+//
+// The returned Iterator is it, unless the stream had already ended, in which case it is closed.
+func Resume[T any](ctx context.Context, it Iterator[T], c Checkpoint[T]) (Iterator[T], error) {
+	if c.end {
+		if err := Close(it); err != nil {
+			return nil, err
+		}
+		return NewSlice[T](nil, nil), nil
+	}
+	if c.key.Ok {
+		sk, ok := it.(Seeker[T])
+		if !ok {
+			return nil, fmt.Errorf("streams: cannot resume %T, it does not implement Seeker", it)
+		}
+		if err := sk.Seek(ctx, c.key.X); err != nil {
+			return nil, err
+		}
+	}
+	if err := Skip(ctx, it, int(c.skip)); err != nil && !IsEOS(err) {
+		return nil, err
+	}
+	return it, nil
+}
+
+// keyPos tracks the position of a sorted Iterator by the elements it has emitted.
+type keyPos[T any] struct {
+	// last is the last element emitted, or the last key passed to Seek.
+	last maybe.Maybe[T]
+	// n is the number of elements equal to last which have been emitted.
+	n uint64
+}
+
+// emitted records that x has been emitted.
+func (p *keyPos[T]) emitted(x T, cmp func(a, b T) int) {
+	if p.last.Ok && cmp(p.last.X, x) == 0 {
+		p.n++
+		return
+	}
+	p.last = maybe.Just(x)
+	p.n = 1
+}
+
+// sought records that the Iterator has been advanced to gteq.
+func (p *keyPos[T]) sought(gteq T, cmp func(a, b T) int) {
+	if !p.last.Ok || cmp(gteq, p.last.X) > 0 {
+		p.last = maybe.Just(gteq)
+		p.n = 0
+	}
+}
+
+func (p keyPos[T]) checkpoint() Checkpoint[T] {
+	return Checkpoint[T]{key: p.last, skip: p.n}
+}
@@ -8,19 +8,33 @@ func (it *concat[T]) Next(ctx context.Context, dst []T) (int, error) {
 	if len(dst) == 0 {
 		return 0, nil
 	}
-	if len(*it) == 0 {
-		return 0, EOS()
-	}
-	if err := NextUnit(ctx, (*it)[0], &dst[0]); err != nil {
-		if IsEOS(err) {
-			*it = (*it)[1:]
-			return it.Next(ctx, dst)
+	for len(*it) > 0 {
+		n, err := (*it)[0].Next(ctx, dst)
+		if err != nil {
+			if IsEOS(err) {
+				// close each input as soon as it is exhausted
+				if err := Close((*it)[0]); err != nil {
+					return 0, err
+				}
+				*it = (*it)[1:]
+				continue
+			}
+			return 0, err
 		}
-		return 0, err
+		return n, nil
 	}
-	return 0, nil
+	return 0, EOS()
+}
+
+// Close closes all of the inputs which have not been exhausted.
+func (it *concat[T]) Close() error {
+	err := closeAll[T](*it)
+	*it = nil
+	return err
 }
 
+// Concat returns an Iterator which emits all the elements of each Iterator in its, in order.
+// Each Iterator is closed as soon as it is exhausted.
 func Concat[T any](its ...Iterator[T]) Iterator[T] {
 	c := concat[T](its)
 	return &c
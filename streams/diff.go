@@ -0,0 +1,118 @@
+package streams
+
+import (
+	"context"
+	"fmt"
+
+	"go.brendoncarroll.net/exp/maybe"
+)
+
+// DeltaOp is the kind of difference described by a Delta
+type DeltaOp uint8
+
+const (
+	// Added means the entry is only in the new stream
+	Added DeltaOp = iota + 1
+	// Removed means the entry is only in the old stream
+	Removed
+	// Changed means the entry is in both streams, with different values
+	Changed
+)
+
+func (op DeltaOp) String() string {
+	switch op {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Changed:
+		return "Changed"
+	default:
+		return fmt.Sprintf("DeltaOp(%d)", uint8(op))
+	}
+}
+
+// Delta is a difference between an old and a new stream.
+// Old is set for Removed and Changed, New is set for Added and Changed.
+type Delta[T any] struct {
+	Op  DeltaOp
+	Old T
+	New T
+}
+
+func (d Delta[T]) String() string {
+	switch d.Op {
+	case Added:
+		return fmt.Sprintf("{+ %v}", d.New)
+	case Removed:
+		return fmt.Sprintf("{- %v}", d.Old)
+	default:
+		return fmt.Sprintf("{%v -> %v}", d.Old, d.New)
+	}
+}
+
+var (
+	_ Iterator[Delta[int]] = &Differ[int]{}
+	_ Seeker[int]          = &Differ[int]{}
+)
+
+// Differ emits the differences between two sorted streams.
+type Differ[T any] struct {
+	j  *OJoiner[T, T]
+	eq func(a, b T) bool
+
+	buf []OJoined[T, T]
+}
+
+// NewDiffer creates a Differ.
+// old and new must be sorted by key according to cmp, and have unique keys.
+// Entries with equal keys are compared with eq, and are only emitted if eq returns false.
+func NewDiffer[T any](old, new Peekable[T], cmp func(a, b T) int, eq func(a, b T) bool) *Differ[T] {
+	return &Differ[T]{
+		j:  NewOJoiner(old, new, cmp),
+		eq: eq,
+	}
+}
+
+func (d *Differ[T]) Next(ctx context.Context, dst []Delta[T]) (int, error) {
+	if len(d.buf) < len(dst) {
+		d.buf = make([]OJoined[T, T], len(dst))
+	}
+	for {
+		n, err := d.j.Next(ctx, d.buf[:len(dst)])
+		if err != nil {
+			return 0, err
+		}
+		var m int
+		for _, oj := range d.buf[:n] {
+			switch {
+			case oj.Left.Ok && oj.Right.Ok:
+				if d.eq(oj.Left.X, oj.Right.X) {
+					continue
+				}
+				dst[m] = Delta[T]{Op: Changed, Old: oj.Left.X, New: oj.Right.X}
+			case oj.Left.Ok:
+				dst[m] = Delta[T]{Op: Removed, Old: oj.Left.X}
+			default:
+				dst[m] = Delta[T]{Op: Added, New: oj.Right.X}
+			}
+			m++
+		}
+		if m > 0 {
+			return m, nil
+		}
+	}
+}
+
+// Seek implements Seeker, by seeking both streams.
+// Callers which know that a range of keys is identical in both streams,
+// for example by comparing hashes, can use Seek to skip over it without reading it.
+// If both streams implement Seeker, then neither stream has to read the range either.
+func (d *Differ[T]) Seek(ctx context.Context, gteq T) error {
+	return d.j.Seek(ctx, OJoined[T, T]{Left: maybe.Just(gteq), Right: maybe.Just(gteq)})
+}
+
+// Close closes both streams
+func (d *Differ[T]) Close() error {
+	return d.j.Close()
+}
@@ -0,0 +1,166 @@
+package streams
+
+import (
+	"context"
+	"sync"
+)
+
+var _ Iterator[Tagged[int]] = &Interleaver[int]{}
+
+// Tagged is an element, along with the index of the input it came from.
+type Tagged[T any] struct {
+	Source int
+	X      T
+}
+
+// Interleaver emits the elements of several Iterators in the order that they arrive.
+// It is created by FanIn.
+//
+// Close must be called to release the goroutines.
+type Interleaver[T any] struct {
+	inputs  []Iterator[T]
+	onError func(source int, err error) error
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	wg      sync.WaitGroup
+	items   chan fanInItem[T]
+
+	// live is the number of inputs which have not ended.
+	live int
+	err  error
+}
+
+type fanInItem[T any] struct {
+	source int
+	x      T
+	err    error
+}
+
+// FanIn returns an Interleaver which reads each of inputs on its own goroutine,
+// so that a slow input does not hold back the others.
+// Elements from the same input are emitted in order, but there is no order between inputs.
+//
+// onError is called when an input returns an error other than EOS.
+// If it returns nil, then the input is dropped and the others continue.
+// Otherwise, all of the inputs are cancelled, and the returned error is emitted after the elements which were
+// already buffered in the Interleaver. The same applies if ctx is cancelled.
+// If onError is nil, then all errors are emitted.
+func FanIn[T any](ctx context.Context, onError func(source int, err error) error, inputs ...Iterator[T]) *Interleaver[T] {
+	if onError == nil {
+		onError = func(_ int, err error) error { return err }
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	f := &Interleaver[T]{
+		inputs:  inputs,
+		onError: onError,
+		ctx:     ctx,
+		cancel:  cancel,
+		items:   make(chan fanInItem[T], len(inputs)),
+		live:    len(inputs),
+	}
+	f.wg.Add(len(inputs))
+	for i := range inputs {
+		go func() {
+			defer f.wg.Done()
+			f.readLoop(i)
+		}()
+	}
+	return f
+}
+
+// readLoop reads from an input until it errors, or the Interleaver is cancelled.
+func (f *Interleaver[T]) readLoop(i int) {
+	buf := make([]T, 16)
+	for {
+		n, err := f.inputs[i].Next(f.ctx, buf)
+		if err != nil {
+			n = 0
+		}
+		for _, x := range buf[:n] {
+			select {
+			case <-f.ctx.Done():
+				return
+			case f.items <- fanInItem[T]{source: i, x: x}:
+			}
+		}
+		if err != nil {
+			select {
+			case <-f.ctx.Done():
+			case f.items <- fanInItem[T]{source: i, err: err}:
+			}
+			return
+		}
+	}
+}
+
+func (f *Interleaver[T]) Next(ctx context.Context, dst []Tagged[T]) (int, error) {
+	var n int
+	for n < len(dst) {
+		ok, err := f.next(ctx, &dst[n], n == 0)
+		if err != nil {
+			if n > 0 {
+				break
+			}
+			return 0, err
+		}
+		if !ok {
+			break
+		}
+		n++
+	}
+	return n, nil
+}
+
+// next reads the next element into dst.
+// If block is false, then next returns false instead of waiting for an element.
+func (f *Interleaver[T]) next(ctx context.Context, dst *Tagged[T], block bool) (bool, error) {
+	for {
+		if f.err != nil {
+			return false, f.err
+		}
+		if f.live == 0 && f.ctx.Err() == nil {
+			f.err = EOS()
+			continue
+		}
+		var item fanInItem[T]
+		if block && f.ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+				return false, ctx.Err()
+			case <-f.ctx.Done():
+				continue
+			case item = <-f.items:
+			}
+		} else {
+			select {
+			case item = <-f.items:
+			default:
+				if f.ctx.Err() != nil {
+					// the elements which arrived before the inputs were cancelled have all been emitted.
+					f.err = context.Cause(f.ctx)
+					continue
+				}
+				return false, nil
+			}
+		}
+		if item.err != nil {
+			f.live--
+			if IsEOS(item.err) || f.ctx.Err() != nil {
+				continue
+			}
+			if err := f.onError(item.source, item.err); err != nil {
+				f.cancel(err)
+			}
+			continue
+		}
+		*dst = Tagged[T]{Source: item.source, X: item.x}
+		return true, nil
+	}
+}
+
+// Close cancels all of the inputs, waits for the goroutines to exit, and then closes the inputs.
+func (f *Interleaver[T]) Close() error {
+	f.cancel(nil)
+	f.wg.Wait()
+	return closeAll[T](f.inputs)
+}
@@ -7,11 +7,17 @@ type filter[T any] struct {
 	pred func(T) bool
 }
 
+// NewFilter returns an Iterator which only emits the elements of x for which pred returns true.
+// If x implements Seeker, then so will the returned Iterator.
 func NewFilter[T any](x Iterator[T], pred func(T) bool) Iterator[T] {
-	return &filter[T]{
+	f := filter[T]{
 		x:    x,
 		pred: pred,
 	}
+	if sk, ok := x.(Seeker[T]); ok {
+		return &seekFilter[T]{filter: f, sk: sk}
+	}
+	return &f
 }
 
 func (f *filter[T]) Next(ctx context.Context, dst []T) (int, error) {
@@ -24,3 +30,17 @@ func (f *filter[T]) Next(ctx context.Context, dst []T) (int, error) {
 		}
 	}
 }
+
+// Close implements Closer
+func (f *filter[T]) Close() error {
+	return Close(f.x)
+}
+
+type seekFilter[T any] struct {
+	filter[T]
+	sk Seeker[T]
+}
+
+func (f *seekFilter[T]) Seek(ctx context.Context, gteq T) error {
+	return f.sk.Seek(ctx, gteq)
+}
@@ -0,0 +1,61 @@
+package streams
+
+import (
+	"context"
+	"errors"
+)
+
+type flatMap[X, Y any] struct {
+	xs  Iterator[X]
+	fn  func(ctx context.Context, x X) (Iterator[Y], error)
+	cur Iterator[Y]
+	x   X
+}
+
+// FlatMap returns an Iterator which calls fn on each element of it, and emits all the elements
+// of the Iterator that fn returns, before moving on to the next element.
+// Each Iterator returned by fn is closed as soon as it is exhausted.
+func FlatMap[X, Y any](it Iterator[X], fn func(ctx context.Context, x X) (Iterator[Y], error)) Iterator[Y] {
+	return &flatMap[X, Y]{xs: it, fn: fn}
+}
+
+func (fm *flatMap[X, Y]) Next(ctx context.Context, dst []Y) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	for {
+		if fm.cur == nil {
+			if err := NextUnit(ctx, fm.xs, &fm.x); err != nil {
+				return 0, err
+			}
+			cur, err := fm.fn(ctx, fm.x)
+			if err != nil {
+				return 0, err
+			}
+			fm.cur = cur
+		}
+		n, err := fm.cur.Next(ctx, dst)
+		if err != nil {
+			if IsEOS(err) {
+				cur := fm.cur
+				fm.cur = nil
+				if err := Close(cur); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			return 0, err
+		}
+		return n, nil
+	}
+}
+
+// Close closes the current inner Iterator, if there is one, and then the outer Iterator.
+func (fm *flatMap[X, Y]) Close() error {
+	var err error
+	if fm.cur != nil {
+		err = Close(fm.cur)
+		fm.cur = nil
+	}
+	return errors.Join(err, Close(fm.xs))
+}
@@ -0,0 +1,152 @@
+package streams
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"go.brendoncarroll.net/exp/sbe"
+)
+
+var (
+	_ Iterator[int] = &FrameReader[int]{}
+	_ Sink[int]     = &FrameWriter[int]{}
+)
+
+// FrameReader is an Iterator which decodes records from an io.Reader.
+// Each record is length prefixed, as written by sbe.AppendLP, or a FrameWriter.
+type FrameReader[T any] struct {
+	r         byteReader
+	unmarshal func(data []byte, dst *T) error
+	maxSize   int
+
+	buf []byte
+	err error
+}
+
+// frameChunkSize is the most that FrameReader grows its buffer by before reading into it.
+const frameChunkSize = 1 << 16
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// NewFrameReader creates a FrameReader.
+// Records larger than maxSize bytes are rejected with an error, if maxSize <= 0 then there is no limit.
+// unmarshal must not retain data after it returns.
+func NewFrameReader[T any](r io.Reader, maxSize int, unmarshal func(data []byte, dst *T) error) *FrameReader[T] {
+	br, ok := r.(byteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &FrameReader[T]{
+		r:         br,
+		unmarshal: unmarshal,
+		maxSize:   maxSize,
+	}
+}
+
+func (fr *FrameReader[T]) Next(ctx context.Context, dst []T) (int, error) {
+	var n int
+	for n < len(dst) {
+		if err := fr.next(&dst[n]); err != nil {
+			if n > 0 {
+				break
+			}
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// next reads a single record.
+// Errors which leave the reader in the middle of a frame are sticky.
+func (fr *FrameReader[T]) next(dst *T) error {
+	if fr.err != nil {
+		return fr.err
+	}
+	l, err := binary.ReadUvarint(fr.r)
+	if err != nil {
+		switch {
+		case errors.Is(err, io.EOF):
+			fr.err = EOS()
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			fr.err = fmt.Errorf("streams: truncated frame length: %w", err)
+		default:
+			fr.err = err
+		}
+		return fr.err
+	}
+	if fr.maxSize > 0 && l > uint64(fr.maxSize) {
+		fr.err = fmt.Errorf("streams: frame of %d bytes exceeds max size of %d", l, fr.maxSize)
+		return fr.err
+	}
+	if l > math.MaxInt {
+		fr.err = fmt.Errorf("streams: frame length %d is too large", l)
+		return fr.err
+	}
+	// the buffer grows as data arrives, so a corrupt length cannot cause a large allocation up front.
+	fr.buf = fr.buf[:0]
+	for len(fr.buf) < int(l) {
+		start := len(fr.buf)
+		chunk := min(int(l)-start, frameChunkSize)
+		fr.buf = slices.Grow(fr.buf, chunk)[:start+chunk]
+		if n, err := io.ReadFull(fr.r, fr.buf[start:]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				err = fmt.Errorf("streams: truncated frame, expected %d bytes, got %d: %w", l, start+n, io.ErrUnexpectedEOF)
+			}
+			fr.err = err
+			return fr.err
+		}
+	}
+	return fr.unmarshal(fr.buf, dst)
+}
+
+// FrameWriter is a Sink which encodes records to an io.Writer.
+// Each record is length prefixed, using sbe.AppendLP.
+type FrameWriter[T any] struct {
+	w       io.Writer
+	marshal func(out []byte, x T) []byte
+	maxSize int
+
+	data, frames []byte
+}
+
+// NewFrameWriter creates a FrameWriter.
+// Records larger than maxSize bytes are rejected with an error, if maxSize <= 0 then there is no limit.
+func NewFrameWriter[T any](w io.Writer, maxSize int, marshal func(out []byte, x T) []byte) *FrameWriter[T] {
+	return &FrameWriter[T]{
+		w:       w,
+		marshal: marshal,
+		maxSize: maxSize,
+	}
+}
+
+// Write encodes all of the records in src, and writes them to the underlying io.Writer with a single call.
+func (fw *FrameWriter[T]) Write(ctx context.Context, src []T) error {
+	fw.frames = fw.frames[:0]
+	for _, x := range src {
+		fw.data = fw.marshal(fw.data[:0], x)
+		if fw.maxSize > 0 && len(fw.data) > fw.maxSize {
+			return fmt.Errorf("streams: record of %d bytes exceeds max size of %d", len(fw.data), fw.maxSize)
+		}
+		fw.frames = sbe.AppendLP(fw.frames, fw.data)
+	}
+	_, err := fw.w.Write(fw.frames)
+	return err
+}
+
+// Flush calls Flush on the underlying io.Writer, if it has a Flush method, like bufio.Writer.
+func (fw *FrameWriter[T]) Flush(ctx context.Context) error {
+	if f, ok := fw.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package streams
+
+import "context"
+
+var (
+	_ Iterator[*Group[int]] = &GroupBy[int]{}
+	_ Peekable[int]         = &Group[int]{}
+	_ Iterator[int]         = &GroupFold[int, int]{}
+)
+
+// GroupBy emits runs of consecutive elements with equal keys as Groups.
+// Each Group is a lazily consumed sub-iterator, which is only valid until the next call to GroupBy.Next.
+// Any elements not read from a Group are skipped.
+type GroupBy[T any] struct {
+	inner Peekable[T]
+	eq    func(a, b T) bool
+
+	cur *Group[T]
+}
+
+// NewGroupBy creates a GroupBy.
+// inner is assumed to be sorted, so that elements with equal keys are adjacent.
+// eq should return true if a and b have equal keys.
+func NewGroupBy[T any](inner Peekable[T], eq func(a, b T) bool) *GroupBy[T] {
+	return &GroupBy[T]{
+		inner: inner,
+		eq:    eq,
+	}
+}
+
+// Next emits at most 1 Group per call, since emitting a Group invalidates the previous one.
+func (gb *GroupBy[T]) Next(ctx context.Context, dst []*Group[T]) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	if gb.cur != nil {
+		if err := gb.cur.drain(ctx); err != nil {
+			return 0, err
+		}
+		gb.cur = nil
+	}
+	g := &Group[T]{parent: gb}
+	if err := gb.inner.Peek(ctx, &g.first); err != nil {
+		return 0, err
+	}
+	gb.cur = g
+	dst[0] = g
+	return 1, nil
+}
+
+// Close implements Closer
+func (gb *GroupBy[T]) Close() error {
+	return Close[T](gb.inner)
+}
+
+// Group is a run of elements with equal keys, emitted by GroupBy.
+type Group[T any] struct {
+	parent *GroupBy[T]
+	first  T
+	done   bool
+}
+
+// First returns the first element in the group.
+// It is available even after the group has been consumed.
+func (g *Group[T]) First() T {
+	return g.first
+}
+
+func (g *Group[T]) Next(ctx context.Context, dst []T) (int, error) {
+	var n int
+	for n < len(dst) {
+		if err := g.Peek(ctx, &dst[n]); err != nil {
+			if n > 0 {
+				break
+			}
+			return 0, err
+		}
+		if err := Skip(ctx, g.parent.inner, 1); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Peek implements Peekable
+func (g *Group[T]) Peek(ctx context.Context, dst *T) error {
+	if g.done || g.parent.cur != g {
+		return EOS()
+	}
+	if err := g.parent.inner.Peek(ctx, dst); err != nil {
+		if IsEOS(err) {
+			g.done = true
+		}
+		return err
+	}
+	if !g.parent.eq(g.first, *dst) {
+		g.done = true
+		return EOS()
+	}
+	return nil
+}
+
+// drain skips the rest of the group
+func (g *Group[T]) drain(ctx context.Context) error {
+	var x T
+	for {
+		if err := g.Peek(ctx, &x); err != nil {
+			if IsEOS(err) {
+				return nil
+			}
+			return err
+		}
+		if err := Skip(ctx, g.parent.inner, 1); err != nil {
+			return err
+		}
+	}
+}
+
+// NewGroupSlices emits runs of consecutive elements with equal keys as slices.
+func NewGroupSlices[T any](inner Iterator[T], eq func(a, b T) bool) *Splitter[T] {
+	return NewSplitter(inner, func(prev, next T) bool {
+		return !eq(prev, next)
+	})
+}
+
+// GroupFold emits a single aggregate for each run of elements with equal keys.
+type GroupFold[T, A any] struct {
+	groups *GroupBy[T]
+	init   func(first T) A
+	fn     func(acc A, x T) A
+}
+
+// NewGroupFold creates a GroupFold.
+// init is called to create the aggregate from the first element of each group,
+// then fn is called to add each of the remaining elements.
+func NewGroupFold[T, A any](inner Peekable[T], eq func(a, b T) bool, init func(first T) A, fn func(acc A, x T) A) *GroupFold[T, A] {
+	return &GroupFold[T, A]{
+		groups: NewGroupBy(inner, eq),
+		init:   init,
+		fn:     fn,
+	}
+}
+
+// NewGroupReduce creates a GroupFold which combines the elements of each group using fn.
+func NewGroupReduce[T any](inner Peekable[T], eq func(a, b T) bool, fn func(a, b T) T) *GroupFold[T, T] {
+	return NewGroupFold(inner, eq, func(first T) T { return first }, fn)
+}
+
+func (gf *GroupFold[T, A]) Next(ctx context.Context, dst []A) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	g, err := Next[*Group[T]](ctx, gf.groups)
+	if err != nil {
+		return 0, err
+	}
+	var x T
+	if err := NextUnit(ctx, g, &x); err != nil {
+		return 0, err
+	}
+	acc := gf.init(x)
+	if err := ForEach(ctx, g, func(x T) error {
+		acc = gf.fn(acc, x)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	dst[0] = acc
+	return 1, nil
+}
+
+// Close implements Closer
+func (gf *GroupFold[T, A]) Close() error {
+	return gf.groups.Close()
+}
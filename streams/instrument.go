@@ -0,0 +1,142 @@
+package streams
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Op identifies a method of an Iterator
+type Op uint8
+
+const (
+	OpNext Op = iota + 1
+	OpPeek
+	OpSeek
+)
+
+func (op Op) String() string {
+	switch op {
+	case OpNext:
+		return "Next"
+	case OpPeek:
+		return "Peek"
+	case OpSeek:
+		return "Seek"
+	default:
+		return fmt.Sprintf("Op(%d)", uint8(op))
+	}
+}
+
+// CallInfo describes a completed call to an instrumented Iterator.
+type CallInfo struct {
+	Op Op
+	// N is the number of elements emitted by Next.
+	N int
+	// Err is the error returned by the call, which may be EOS.
+	Err error
+	// Duration is the time spent blocked in the call.
+	Duration time.Duration
+}
+
+// Hooks receives events from an Iterator created by Instrument.
+// Implementations can record metrics, or start and end trace spans.
+type Hooks interface {
+	// Begin is called before each call to the Iterator.
+	// The returned context is passed to the Iterator, and to End.
+	Begin(ctx context.Context, op Op) context.Context
+	// End is called after each call to the Iterator returns.
+	End(ctx context.Context, info CallInfo)
+}
+
+// Instrument returns an Iterator which reports every call to it to hooks.
+// The returned Iterator implements Peekable and Seeker if it does.
+func Instrument[T any](it Iterator[T], hooks Hooks) Iterator[T] {
+	return wrapOp[T](&instrumented[T]{inner: it, hooks: hooks}, isPeekable(it), isSeeker(it))
+}
+
+type instrumented[T any] struct {
+	inner Iterator[T]
+	hooks Hooks
+}
+
+func (in *instrumented[T]) Next(ctx context.Context, dst []T) (n int, err error) {
+	ctx, end := in.begin(ctx, OpNext)
+	defer func() { end(n, err) }()
+	return in.inner.Next(ctx, dst)
+}
+
+func (in *instrumented[T]) peek(ctx context.Context, dst *T) (err error) {
+	ctx, end := in.begin(ctx, OpPeek)
+	defer func() { end(0, err) }()
+	return in.inner.(Peekable[T]).Peek(ctx, dst)
+}
+
+func (in *instrumented[T]) seek(ctx context.Context, gteq T) (err error) {
+	ctx, end := in.begin(ctx, OpSeek)
+	defer func() { end(0, err) }()
+	return in.inner.(Seeker[T]).Seek(ctx, gteq)
+}
+
+func (in *instrumented[T]) Close() error {
+	return Close(in.inner)
+}
+
+func (in *instrumented[T]) begin(ctx context.Context, op Op) (context.Context, func(n int, err error)) {
+	ctx = in.hooks.Begin(ctx, op)
+	start := time.Now()
+	return ctx, func(n int, err error) {
+		in.hooks.End(ctx, CallInfo{
+			Op:       op,
+			N:        n,
+			Err:      err,
+			Duration: time.Since(start),
+		})
+	}
+}
+
+var _ Hooks = &Stats{}
+
+// Stats is a Hooks which accumulates counters.
+// It is safe to read the counters while the Iterator is in use.
+type Stats struct {
+	// Elements is the number of elements emitted by Next.
+	Elements atomic.Int64
+	// Batches is the number of calls to Next which emitted elements.
+	Batches atomic.Int64
+	// Errors is the number of calls which returned an error other than EOS.
+	Errors atomic.Int64
+
+	// NextNanos, PeekNanos and SeekNanos are the total time spent blocked in each method.
+	NextNanos atomic.Int64
+	PeekNanos atomic.Int64
+	SeekNanos atomic.Int64
+}
+
+// Begin implements Hooks
+func (s *Stats) Begin(ctx context.Context, op Op) context.Context {
+	return ctx
+}
+
+// End implements Hooks
+func (s *Stats) End(ctx context.Context, info CallInfo) {
+	switch info.Op {
+	case OpNext:
+		s.NextNanos.Add(int64(info.Duration))
+	case OpPeek:
+		s.PeekNanos.Add(int64(info.Duration))
+	case OpSeek:
+		s.SeekNanos.Add(int64(info.Duration))
+	}
+	if info.Err != nil {
+		if !IsEOS(info.Err) {
+			s.Errors.Add(1)
+		}
+		return
+	}
+	if info.N > 0 {
+		s.Elements.Add(int64(info.N))
+		s.Batches.Add(1)
+	}
+}
@@ -2,6 +2,7 @@ package streams
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"go.brendoncarroll.net/exp/maybe"
@@ -40,6 +41,13 @@ func (oj OJoined[L, R]) String() string {
 	}
 }
 
+var (
+	_ Iterator[OJoined[int, int]] = &OJoiner[int, int]{}
+	_ Seeker[OJoined[int, int]]   = &OJoiner[int, int]{}
+
+	_ Checkpointer[OJoined[int, int]] = &OJoiner[int, int]{}
+)
+
 type OJoiner[L, R any] struct {
 	lit Peekable[L]
 	rit Peekable[R]
@@ -66,6 +74,9 @@ func (j *OJoiner[A, B]) Next(ctx context.Context, dsts []OJoined[A, B]) (int, er
 				leftEmpty = true
 				break
 			}
+			if n > 0 {
+				return n, nil
+			}
 			return 0, err
 		}
 		if err := j.rit.Peek(ctx, &dsts[i].Right.X); err != nil {
@@ -73,6 +84,9 @@ func (j *OJoiner[A, B]) Next(ctx context.Context, dsts []OJoined[A, B]) (int, er
 				rightEmpty = true
 				break
 			}
+			if n > 0 {
+				return n, nil
+			}
 			return 0, err
 		}
 		c := j.cmp(dsts[i].Left.X, dsts[i].Right.X)
@@ -106,8 +120,12 @@ func (j *OJoiner[A, B]) Next(ctx context.Context, dsts []OJoined[A, B]) (int, er
 	// emit from right
 	if leftEmpty {
 		for i := range dsts2 {
+			dsts2[i].Reset()
 			if err := NextUnit(ctx, j.rit, &dsts2[i].Right.X); err != nil {
-				return n, err
+				if n > 0 {
+					return n, nil
+				}
+				return 0, err
 			}
 			dsts2[i].Right.Ok = true
 			n++
@@ -116,8 +134,12 @@ func (j *OJoiner[A, B]) Next(ctx context.Context, dsts []OJoined[A, B]) (int, er
 	// emit from left
 	if rightEmpty {
 		for i := range dsts2 {
+			dsts2[i].Reset()
 			if err := NextUnit(ctx, j.lit, &dsts2[i].Left.X); err != nil {
-				return n, err
+				if n > 0 {
+					return n, nil
+				}
+				return 0, err
 			}
 			dsts2[i].Left.Ok = true
 			n++
@@ -126,8 +148,171 @@ func (j *OJoiner[A, B]) Next(ctx context.Context, dsts []OJoined[A, B]) (int, er
 	return n, nil
 }
 
+// Seek implements Seeker.
+// Both inputs are advanced past the elements which are less than the key in gteq.
+// The key can be set on either side of gteq, or on both if they are equal.
+// If only one side is set, then the input on the same side must implement Seeker.
+func (j *OJoiner[L, R]) Seek(ctx context.Context, gteq OJoined[L, R]) error {
+	if err := seekCross(ctx, j.lit, gteq.Left, gteq.Right, j.cmp); err != nil {
+		return err
+	}
+	return seekCross(ctx, j.rit, gteq.Right, gteq.Left, func(r R, l L) int {
+		return -j.cmp(l, r)
+	})
+}
+
+// Checkpoint implements Checkpointer.
+// The Checkpoint contains the key of the next row, taken from the heads of the inputs.
+// Resuming from it requires the same Seekers as Seek, and assumes that keys are unique within each input.
+func (j *OJoiner[L, R]) Checkpoint(ctx context.Context) (Checkpoint[OJoined[L, R]], error) {
+	var next OJoined[L, R]
+	if err := j.lit.Peek(ctx, &next.Left.X); err == nil {
+		next.Left.Ok = true
+	} else if !IsEOS(err) {
+		return Checkpoint[OJoined[L, R]]{}, err
+	}
+	if err := j.rit.Peek(ctx, &next.Right.X); err == nil {
+		next.Right.Ok = true
+	} else if !IsEOS(err) {
+		return Checkpoint[OJoined[L, R]]{}, err
+	}
+	// only keep the side(s) with the lowest key, so that next is the key of the next row.
+	if next.Left.Ok && next.Right.Ok {
+		switch c := j.cmp(next.Left.X, next.Right.X); {
+		case c < 0:
+			next.Right = maybe.Nothing[R]()
+		case c > 0:
+			next.Left = maybe.Nothing[L]()
+		}
+	}
+	if !next.Left.Ok && !next.Right.Ok {
+		return Checkpoint[OJoined[L, R]]{end: true}, nil
+	}
+	return Checkpoint[OJoined[L, R]]{key: maybe.Just(next)}, nil
+}
+
+// Close closes both inputs
+func (j *OJoiner[L, R]) Close() error {
+	return errors.Join(Close[L](j.lit), Close[R](j.rit))
+}
+
+// seekCross seeks it to a key which is given as an A, a B, or both.
+// If a is set, then it must implement Seeker[A]
+// If b is set, then Seeker[B] is used, or cmp if it does not implement Seeker[B].
+func seekCross[A, B any](ctx context.Context, it Peekable[A], a maybe.Maybe[A], b maybe.Maybe[B], cmp func(A, B) int) error {
+	if a.Ok {
+		if sk, ok := it.(Seeker[A]); ok {
+			return sk.Seek(ctx, a.X)
+		}
+	}
+	if !b.Ok {
+		if a.Ok {
+			return fmt.Errorf("streams: cannot seek %T, it does not implement Seeker", it)
+		}
+		return nil
+	}
+	if sk, ok := it.(Seeker[B]); ok {
+		return sk.Seek(ctx, b.X)
+	}
+	var x A
+	for {
+		if err := it.Peek(ctx, &x); err != nil {
+			if IsEOS(err) {
+				return nil
+			}
+			return err
+		}
+		if cmp(x, b.X) >= 0 {
+			return nil
+		}
+		if err := Skip(ctx, it, 1); err != nil {
+			return err
+		}
+	}
+}
+
 // IJoined is the result of an inner join
 type IJoined[L, R any] struct {
 	Left  L
 	Right R
 }
+
+var _ Iterator[IJoined[int, int]] = &IJoiner[int, int]{}
+
+// IJoiner performs an inner join on two sorted Iterators.
+type IJoiner[L, R any] struct {
+	lit Peekable[L]
+	rit Peekable[R]
+	cmp func(L, R) int
+}
+
+// NewIJoiner returns an Iterator that performs an inner join
+// on lit and rit, which are both assumed to be sorted in increasing order.
+// Only pairs which compare equal are emitted.
+//
+// If lit implements Seeker[R] or rit implements Seeker[L], then Seek is used
+// to skip past elements which cannot have a match.
+// When L and R are the same type, any Seeker[L] will do.
+func NewIJoiner[L, R any](lit Peekable[L], rit Peekable[R], compare func(L, R) int) *IJoiner[L, R] {
+	return &IJoiner[L, R]{
+		lit: lit,
+		rit: rit,
+		cmp: compare,
+	}
+}
+
+func (j *IJoiner[L, R]) Next(ctx context.Context, dsts []IJoined[L, R]) (int, error) {
+	var n int
+	for n < len(dsts) {
+		if err := j.next(ctx, &dsts[n]); err != nil {
+			if n > 0 {
+				break
+			}
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (j *IJoiner[L, R]) next(ctx context.Context, dst *IJoined[L, R]) error {
+	for {
+		if err := j.lit.Peek(ctx, &dst.Left); err != nil {
+			return err
+		}
+		if err := j.rit.Peek(ctx, &dst.Right); err != nil {
+			return err
+		}
+		c := j.cmp(dst.Left, dst.Right)
+		switch {
+		case c < 0:
+			// left is behind
+			if sk, ok := j.lit.(Seeker[R]); ok {
+				if err := sk.Seek(ctx, dst.Right); err != nil {
+					return err
+				}
+			} else if err := Skip(ctx, j.lit, 1); err != nil {
+				return err
+			}
+		case c > 0:
+			// right is behind
+			if sk, ok := j.rit.(Seeker[L]); ok {
+				if err := sk.Seek(ctx, dst.Left); err != nil {
+					return err
+				}
+			} else if err := Skip(ctx, j.rit, 1); err != nil {
+				return err
+			}
+		default:
+			if err := Skip(ctx, j.lit, 1); err != nil {
+				return err
+			}
+			return Skip(ctx, j.rit, 1)
+		}
+	}
+}
+
+// Close closes both inputs
+func (j *IJoiner[L, R]) Close() error {
+	return errors.Join(Close[L](j.lit), Close[R](j.rit))
+}
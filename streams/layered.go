@@ -0,0 +1,84 @@
+package streams
+
+import "context"
+
+var (
+	_ Iterator[int] = &Layered[int]{}
+	_ Peekable[int] = &Layered[int]{}
+	_ Seeker[int]   = &Layered[int]{}
+)
+
+// Layered merges sorted layers, such as the levels of a log-structured merge tree.
+// When more than one layer contains a key, only the element from the newest layer is emitted.
+// Elements which are tombstones are not emitted, but still shadow the same key in older layers.
+type Layered[T any] struct {
+	merger      *Merger[T]
+	isTombstone func(T) bool
+}
+
+// NewLayered creates a Layered.
+// layers must be ordered from newest to oldest, and each must be sorted according to cmp.
+// Each layer should contain each key at most once.
+// isTombstone reports whether an element marks its key as deleted. If it is nil, there are no tombstones.
+func NewLayered[T any](layers []Peekable[T], cmp func(a, b T) int, isTombstone func(T) bool) *Layered[T] {
+	if isTombstone == nil {
+		isTombstone = func(T) bool { return false }
+	}
+	// Merger resolves equal elements in the order of their inputs, so the first is the newest.
+	newest := func(a, _ T) T { return a }
+	return &Layered[T]{
+		merger:      NewResolvingMerger(layers, cmp, newest),
+		isTombstone: isTombstone,
+	}
+}
+
+func (l *Layered[T]) Next(ctx context.Context, dst []T) (int, error) {
+	var n int
+	for n < len(dst) {
+		if err := l.next(ctx, &dst[n]); err != nil {
+			if n > 0 {
+				break
+			}
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (l *Layered[T]) next(ctx context.Context, dst *T) error {
+	for {
+		if err := l.merger.next(ctx, dst); err != nil {
+			return err
+		}
+		if !l.isTombstone(*dst) {
+			return nil
+		}
+	}
+}
+
+// Peek implements Peekable.
+// Tombstones at the front of the stream are consumed.
+func (l *Layered[T]) Peek(ctx context.Context, dst *T) error {
+	for {
+		if err := l.merger.Peek(ctx, dst); err != nil {
+			return err
+		}
+		if !l.isTombstone(*dst) {
+			return nil
+		}
+		if err := l.merger.next(ctx, dst); err != nil {
+			return err
+		}
+	}
+}
+
+// Seek implements Seeker
+func (l *Layered[T]) Seek(ctx context.Context, gteq T) error {
+	return l.merger.Seek(ctx, gteq)
+}
+
+// Close closes all of the layers
+func (l *Layered[T]) Close() error {
+	return l.merger.Close()
+}
@@ -26,3 +26,8 @@ func (m Map[X, Y]) Next(ctx context.Context, dst []Y) (int, error) {
 	m.fn(&dst[0], m.x)
 	return 1, nil
 }
+
+// Close implements Closer
+func (m *Map[X, Y]) Close() error {
+	return Close(m.xs)
+}
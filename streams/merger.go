@@ -3,67 +3,242 @@ package streams
 import (
 	"context"
 
+	"go.brendoncarroll.net/exp/heaps"
 	"go.brendoncarroll.net/exp/maybe"
 )
 
 var (
 	_ Iterator[int] = &Merger[int]{}
 	_ Peekable[int] = &Merger[int]{}
+	_ Seeker[int]   = &Merger[int]{}
+	_ Closer        = &Merger[int]{}
+
+	_ Checkpointer[int] = &Merger[int]{}
 )
 
 // Merger implements the merge part of the Mergesort algorithm.
+// The heads of the inputs are kept in a heap, so selecting the next element
+// costs O(log k) for k inputs.
 type Merger[T any] struct {
-	inputs []Peekable[T]
-	cmp    func(a, b T) int
+	inputs  []Peekable[T]
+	cmp     func(a, b T) int
+	resolve func(a, b T) T
+
+	// heads[i] holds the last value peeked from inputs[i]
+	heads []T
+	// heap contains the indices of the inputs which have a valid head.
+	heap heaps.Heap[int]
+	// stale contains the indices of the inputs which must be peeked
+	// before the heap can be used.
+	stale []int
+
+	// pending is a resolved element which has not been emitted yet,
+	// because the inputs in group have not all been advanced past it.
+	pending maybe.Maybe[T]
+	group   []int
+
+	// pos tracks the last element emitted, for Checkpoint.
+	pos keyPos[T]
 }
 
 // NewMerger creates a new merging stream and returns it.
 // cmp is used to determine which element should be emitted next.
+// Elements which compare equal are emitted in the order of their inputs.
 func NewMerger[T any](inputs []Peekable[T], cmp func(a, b T) int) *Merger[T] {
-	return &Merger[T]{
+	m := &Merger[T]{
 		inputs: inputs,
 		cmp:    cmp,
+		heads:  make([]T, len(inputs)),
+		stale:  make([]int, len(inputs)),
 	}
+	m.heap = heaps.New(m.lt)
+	for i := range m.stale {
+		m.stale[i] = len(inputs) - 1 - i
+	}
+	return m
+}
+
+// NewResolvingMerger creates a Merger which emits a single element for each group of
+// elements which compare equal.
+// resolve is called to combine equal elements, in the order of their inputs.
+func NewResolvingMerger[T any](inputs []Peekable[T], cmp func(a, b T) int, resolve func(a, b T) T) *Merger[T] {
+	m := NewMerger(inputs, cmp)
+	m.resolve = resolve
+	return m
 }
 
 func (sm *Merger[T]) Next(ctx context.Context, dst []T) (int, error) {
-	sr, err := sm.selectStream(ctx)
-	if err != nil {
-		return 0, err
+	var n int
+	for n < len(dst) {
+		if err := sm.next(ctx, &dst[n]); err != nil {
+			if n > 0 {
+				break
+			}
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (sm *Merger[T]) next(ctx context.Context, dst *T) error {
+	if sm.resolve != nil {
+		return sm.nextResolved(ctx, dst)
+	}
+	if err := sm.fill(ctx); err != nil {
+		return err
+	}
+	if sm.heap.Len() == 0 {
+		return EOS()
+	}
+	i := sm.heap.Pop()
+	sm.stale = append(sm.stale, i)
+	if err := NextUnit(ctx, sm.inputs[i], dst); err != nil {
+		return err
+	}
+	sm.pos.emitted(*dst, sm.cmp)
+	return nil
+}
+
+// nextResolved resolves the group of equal heads before advancing any of their inputs.
+// If advancing an input fails, the resolved element is kept in pending, so that a retry can finish the group.
+func (sm *Merger[T]) nextResolved(ctx context.Context, dst *T) error {
+	if !sm.pending.Ok {
+		if err := sm.fill(ctx); err != nil {
+			return err
+		}
+		if sm.heap.Len() == 0 {
+			return EOS()
+		}
+		i := sm.heap.Pop()
+		x := sm.heads[i]
+		sm.group = append(sm.group[:0], i)
+		for sm.heap.Len() > 0 {
+			j := sm.heap.Peek()
+			if sm.cmp(sm.heads[j], x) != 0 {
+				break
+			}
+			sm.group = append(sm.group, sm.heap.Pop())
+			x = sm.resolve(x, sm.heads[j])
+		}
+		sm.pending = maybe.Just(x)
+	}
+	if err := sm.advanceGroup(ctx); err != nil {
+		return err
 	}
-	if err := NextUnit(ctx, sr, &dst[0]); err != nil {
-		return 0, err
+	*dst = sm.pending.X
+	sm.pending = maybe.Nothing[T]()
+	sm.pos.emitted(*dst, sm.cmp)
+	return nil
+}
+
+// advanceGroup skips the heads of the inputs in group, which have been resolved into pending.
+func (sm *Merger[T]) advanceGroup(ctx context.Context) error {
+	for len(sm.group) > 0 {
+		j := sm.group[0]
+		if err := Skip(ctx, sm.inputs[j], 1); err != nil {
+			return err
+		}
+		sm.stale = append(sm.stale, j)
+		sm.group = sm.group[1:]
 	}
-	return 1, nil
+	return nil
 }
 
 func (sm *Merger[T]) Peek(ctx context.Context, dst *T) error {
-	sr, err := sm.selectStream(ctx)
-	if err != nil {
+	if sm.pending.Ok {
+		*dst = sm.pending.X
+		return nil
+	}
+	if err := sm.fill(ctx); err != nil {
+		return err
+	}
+	if sm.heap.Len() == 0 {
+		return EOS()
+	}
+	i := sm.heap.Peek()
+	if err := sm.inputs[i].Peek(ctx, dst); err != nil {
 		return err
 	}
-	return sr.Peek(ctx, dst)
+	if sm.resolve == nil {
+		return nil
+	}
+	// the other equal heads have to be combined without consuming them.
+	popped := []int{sm.heap.Pop()}
+	for sm.heap.Len() > 0 {
+		j := sm.heap.Peek()
+		if sm.cmp(sm.heads[j], *dst) != 0 {
+			break
+		}
+		popped = append(popped, sm.heap.Pop())
+		*dst = sm.resolve(*dst, sm.heads[j])
+	}
+	for _, j := range popped {
+		sm.heap.Push(j)
+	}
+	return nil
 }
 
-// selectStream will never return an ended stream
-func (sm *Merger[T]) selectStream(ctx context.Context) (Peekable[T], error) {
-	var minTMaybe maybe.Maybe[T]
-	nextIndex := len(sm.inputs)
-	var ent T
-	for i, sr := range sm.inputs {
-		if err := sr.Peek(ctx, &ent); err != nil {
-			if IsEOS(err) {
-				continue
-			}
-			return nil, err
+// Seek implements Seeker.
+// Seek is passed down to each input which could contain elements < gteq.
+// Inputs which do not implement Seeker are advanced with Peek and Skip.
+func (sm *Merger[T]) Seek(ctx context.Context, gteq T) error {
+	if sm.pending.Ok {
+		if sm.cmp(sm.pending.X, gteq) >= 0 {
+			return nil
+		}
+		if err := sm.advanceGroup(ctx); err != nil {
+			return err
 		}
-		if !minTMaybe.Ok || sm.cmp(ent, minTMaybe.X) <= 0 {
-			minTMaybe = maybe.Just(ent)
-			nextIndex = i
+		sm.pending = maybe.Nothing[T]()
+	}
+	for sm.heap.Len() > 0 && sm.cmp(sm.heads[sm.heap.Peek()], gteq) < 0 {
+		sm.stale = append(sm.stale, sm.heap.Pop())
+	}
+	for _, i := range sm.stale {
+		if err := Seek(ctx, sm.inputs[i], gteq, sm.cmp); err != nil {
+			return err
 		}
 	}
-	if nextIndex < len(sm.inputs) {
-		return sm.inputs[nextIndex], nil
+	sm.pos.sought(gteq, sm.cmp)
+	return nil
+}
+
+// Checkpoint implements Checkpointer.
+// The Checkpoint contains the last element emitted, so T must be serializable
+// to use the Checkpoint across processes.
+// Elements which compare equal are counted, so inputs may contain duplicates.
+func (sm *Merger[T]) Checkpoint(ctx context.Context) (Checkpoint[T], error) {
+	return sm.pos.checkpoint(), nil
+}
+
+// Close closes all of the inputs
+func (sm *Merger[T]) Close() error {
+	return closeAll[T](sm.inputs)
+}
+
+// fill peeks all of the stale inputs and adds them to the heap.
+// Inputs which have ended are dropped.
+func (sm *Merger[T]) fill(ctx context.Context) error {
+	for len(sm.stale) > 0 {
+		i := sm.stale[len(sm.stale)-1]
+		if err := sm.inputs[i].Peek(ctx, &sm.heads[i]); err != nil {
+			if !IsEOS(err) {
+				return err
+			}
+		} else {
+			sm.heap.Push(i)
+		}
+		sm.stale = sm.stale[:len(sm.stale)-1]
+	}
+	return nil
+}
+
+// lt orders inputs by their heads, breaking ties with the input index.
+func (sm *Merger[T]) lt(i, j int) bool {
+	c := sm.cmp(sm.heads[i], sm.heads[j])
+	if c != 0 {
+		return c < 0
 	}
-	return nil, EOS()
+	return i < j
 }
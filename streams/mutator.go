@@ -4,7 +4,13 @@ import (
 	"context"
 )
 
-var _ Iterator[int] = &Mutator[int]{}
+var (
+	_ Iterator[int] = &Mutator[int]{}
+	_ Closer        = &Mutator[int]{}
+	_ Iterator[int] = &SeekMutator[int]{}
+	_ Seeker[int]   = &SeekMutator[int]{}
+	_ Closer        = &SeekMutator[int]{}
+)
 
 // Mutator edits or drops element in a stream.
 // The inner stream and the Mutator contain elements of the same type.
@@ -32,3 +38,31 @@ func (fm *Mutator[T]) Next(ctx context.Context, dst []T) (int, error) {
 		}
 	}
 }
+
+// Close implements Closer
+func (fm *Mutator[T]) Close() error {
+	return Close(fm.x)
+}
+
+// SeekMutator is a Mutator over a Seeker, which forwards Seek to it.
+type SeekMutator[T any] struct {
+	Mutator[T]
+	sk Seeker[T]
+}
+
+// NewSeekMutator creates a new SeekMutator stream.
+// fn must not change the order of the elements it keeps.
+func NewSeekMutator[T any, I interface {
+	Iterator[T]
+	Seeker[T]
+}](x I, fn func(dst *T) bool) *SeekMutator[T] {
+	return &SeekMutator[T]{
+		Mutator: Mutator[T]{x: x, fn: fn},
+		sk:      x,
+	}
+}
+
+// Seek implements Seeker by forwarding to the inner Iterator.
+func (fm *SeekMutator[T]) Seek(ctx context.Context, gteq T) error {
+	return fm.sk.Seek(ctx, gteq)
+}
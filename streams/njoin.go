@@ -0,0 +1,104 @@
+package streams
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"go.brendoncarroll.net/exp/maybe"
+)
+
+var (
+	_ Iterator[[]maybe.Maybe[int]] = &NJoiner[int]{}
+	_ Seeker[[]maybe.Maybe[int]]   = &NJoiner[int]{}
+)
+
+// NJoiner performs an outer join on any number of sorted Iterators.
+// It emits one row for each distinct key, with a slot for each input.
+// A slot is set if that input contains the key, and is Nothing otherwise.
+type NJoiner[T any] struct {
+	inputs []Peekable[T]
+	cmp    func(a, b T) int
+	heads  []maybe.Maybe[T]
+}
+
+// NewNJoiner returns an Iterator that performs an outer join on inputs,
+// which are all assumed to be sorted in increasing order according to cmp.
+// Rows are written into the slices passed to Next, which are grown as needed.
+func NewNJoiner[T any](inputs []Peekable[T], cmp func(a, b T) int) *NJoiner[T] {
+	return &NJoiner[T]{
+		inputs: inputs,
+		cmp:    cmp,
+		heads:  make([]maybe.Maybe[T], len(inputs)),
+	}
+}
+
+func (j *NJoiner[T]) Next(ctx context.Context, dst [][]maybe.Maybe[T]) (int, error) {
+	var n int
+	for n < len(dst) {
+		if err := j.next(ctx, &dst[n]); err != nil {
+			if n > 0 {
+				break
+			}
+			return 0, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (j *NJoiner[T]) next(ctx context.Context, dst *[]maybe.Maybe[T]) error {
+	// find the lowest key among the heads of the inputs
+	lowest := -1
+	for i, in := range j.inputs {
+		j.heads[i].Ok = false
+		if err := in.Peek(ctx, &j.heads[i].X); err != nil {
+			if IsEOS(err) {
+				continue
+			}
+			return err
+		}
+		j.heads[i].Ok = true
+		if lowest < 0 || j.cmp(j.heads[i].X, j.heads[lowest].X) < 0 {
+			lowest = i
+		}
+	}
+	if lowest < 0 {
+		return EOS()
+	}
+	row := slices.Grow((*dst)[:0], len(j.inputs))[:len(j.inputs)]
+	clear(row)
+	for i, h := range j.heads {
+		if !h.Ok || j.cmp(h.X, j.heads[lowest].X) != 0 {
+			continue
+		}
+		if err := Skip(ctx, j.inputs[i], 1); err != nil {
+			return err
+		}
+		row[i] = h
+	}
+	*dst = row
+	return nil
+}
+
+// Seek implements Seeker.
+// The key is taken from the first slot of gteq which is set, and each input is advanced past the elements less than it.
+func (j *NJoiner[T]) Seek(ctx context.Context, gteq []maybe.Maybe[T]) error {
+	for _, k := range gteq {
+		if !k.Ok {
+			continue
+		}
+		for _, in := range j.inputs {
+			if err := Seek(ctx, in, k.X, j.cmp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("streams: NJoiner.Seek called without a key")
+}
+
+// Close closes all of the inputs
+func (j *NJoiner[T]) Close() error {
+	return closeAll[T](j.inputs)
+}
@@ -0,0 +1,176 @@
+package streams
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var (
+	_ Iterator[int] = &Pager[int, string]{}
+	_ Peekable[int] = &Pager[int, string]{}
+)
+
+// RetryPolicy decides whether an operation which failed with err should be retried.
+// attempt is the number of attempts which have failed so far, starting at 1.
+// RetryPolicy returns how long to wait before the next attempt, and false if err should not be retried.
+type RetryPolicy func(attempt int, err error) (time.Duration, bool)
+
+// Pager emits the elements of a paginated source, such as a remote API.
+// It is created by Paginate.
+//
+// Close must be called to release the background goroutine.
+type Pager[T, C any] struct {
+	fetch func(ctx context.Context, cursor C) ([]T, C, error)
+	retry RetryPolicy
+	start C
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+	wg     sync.WaitGroup
+	pages  chan pagerPage[T, C]
+
+	// cur is the page being consumed, and pos is the index of its next element.
+	cur pagerPage[T, C]
+	pos int
+	err error
+}
+
+type pagerPage[T, C any] struct {
+	cursor C
+	items  []T
+	next   C
+	err    error
+}
+
+// Paginate returns a Pager which emits the items of each page returned by fetch, starting from the page at start.
+// fetch returns the items on the page at cursor, and the cursor of the following page.
+// After the last page, fetch should return EOS. It may also return the items of the last page along with EOS.
+//
+// The next page is fetched on a background goroutine while the current page is consumed.
+// The goroutine is started on the first call to Next or Peek, and stops when ctx is cancelled or the Pager is closed.
+// If retry is not nil, then it is called when fetch returns an error other than EOS, to decide whether to try again.
+func Paginate[T, C any](ctx context.Context, start C, fetch func(ctx context.Context, cursor C) ([]T, C, error), retry RetryPolicy) *Pager[T, C] {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Pager[T, C]{
+		fetch:  fetch,
+		retry:  retry,
+		start:  start,
+		ctx:    ctx,
+		cancel: cancel,
+		pages:  make(chan pagerPage[T, C]),
+		cur:    pagerPage[T, C]{next: start},
+	}
+}
+
+func (p *Pager[T, C]) Next(ctx context.Context, dst []T) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	if err := p.fill(ctx); err != nil {
+		return 0, err
+	}
+	n := copy(dst, p.cur.items[p.pos:])
+	p.pos += n
+	return n, nil
+}
+
+// Peek implements Peekable
+func (p *Pager[T, C]) Peek(ctx context.Context, dst *T) error {
+	if err := p.fill(ctx); err != nil {
+		return err
+	}
+	*dst = p.cur.items[p.pos]
+	return nil
+}
+
+// Cursor returns the cursor of the page which contains the next element, and the index of the element in that page.
+// A scan can be resumed by passing the cursor to Paginate, and skipping that many elements.
+// If fetching a page failed, then Cursor returns the cursor of that page.
+func (p *Pager[T, C]) Cursor() (C, int) {
+	if p.pos < len(p.cur.items) {
+		return p.cur.cursor, p.pos
+	}
+	return p.cur.next, 0
+}
+
+// fill ensures that the current page has an element at pos, receiving pages as needed.
+func (p *Pager[T, C]) fill(ctx context.Context) error {
+	for p.pos >= len(p.cur.items) {
+		if p.err != nil {
+			return p.err
+		}
+		p.once.Do(func() {
+			p.wg.Add(1)
+			go func() {
+				defer p.wg.Done()
+				defer close(p.pages)
+				p.fetchLoop()
+			}()
+		})
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case page, ok := <-p.pages:
+			if !ok {
+				// the background goroutine only exits without an error when it is cancelled.
+				p.err = p.ctx.Err()
+				continue
+			}
+			if page.err != nil && !IsEOS(page.err) {
+				// keep the last good page, so that Cursor points at the page which failed.
+				p.err = page.err
+				continue
+			}
+			p.cur = page
+			p.pos = 0
+			p.err = page.err
+		}
+	}
+	return nil
+}
+
+// fetchLoop fetches pages until there is an error, or the Pager is closed.
+func (p *Pager[T, C]) fetchLoop() {
+	cursor := p.start
+	for {
+		page := pagerPage[T, C]{cursor: cursor}
+		page.items, page.next, page.err = p.fetchPage(cursor)
+		select {
+		case <-p.ctx.Done():
+			return
+		case p.pages <- page:
+		}
+		if page.err != nil {
+			return
+		}
+		cursor = page.next
+	}
+}
+
+// fetchPage calls fetch, retrying according to the RetryPolicy.
+func (p *Pager[T, C]) fetchPage(cursor C) ([]T, C, error) {
+	for attempt := 1; ; attempt++ {
+		items, next, err := p.fetch(p.ctx, cursor)
+		if err == nil || IsEOS(err) || p.retry == nil {
+			return items, next, err
+		}
+		delay, ok := p.retry(attempt, err)
+		if !ok {
+			return items, next, err
+		}
+		select {
+		case <-p.ctx.Done():
+			return nil, next, p.ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Close stops the background goroutine, and waits for it to exit.
+func (p *Pager[T, C]) Close() error {
+	p.cancel()
+	p.wg.Wait()
+	return nil
+}
@@ -0,0 +1,245 @@
+package streams
+
+import (
+	"context"
+	"sync"
+)
+
+var _ Iterator[int] = &ParallelMap[float64, int]{}
+
+// ParallelMap is like Map, but calls its function on several goroutines at once.
+// Elements are emitted in the same order as the input.
+//
+// Close must be called to release the goroutines.
+type ParallelMap[X, Y any] struct {
+	xs     Iterator[X]
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	// dispatchCtx is cancelled to stop reading from xs, once an element has failed.
+	dispatchCtx  context.Context
+	stopDispatch context.CancelCauseFunc
+	wg           sync.WaitGroup
+	// order holds the results in the order of the input.
+	order chan *pmSlot[Y]
+
+	mu sync.Mutex
+	// inflight holds the cancel functions for the elements which are being mapped, by index.
+	inflight map[int]context.CancelCauseFunc
+	// failed is the index of the first element which failed, or -1.
+	failed  int
+	failErr error
+
+	head *pmSlot[Y]
+	err  error
+}
+
+// pmSlot holds the result for a single element.
+// done is closed once y and err have been set.
+type pmSlot[Y any] struct {
+	done chan struct{}
+	y    Y
+	err  error
+}
+
+type pmJob[X, Y any] struct {
+	ctx  context.Context
+	idx  int
+	x    X
+	slot *pmSlot[Y]
+}
+
+// NewParallelMap creates a ParallelMap which calls fn on numWorkers goroutines.
+// At most 2*numWorkers results are buffered ahead of the consumer.
+//
+// If fn returns an error, then the calls to fn for later elements are cancelled,
+// and Next will return that error once all the elements before it have been emitted.
+// If ctx is cancelled, then all in-flight calls to fn are cancelled.
+// The background goroutines call xs.Next, so xs must not be used by anything else.
+func NewParallelMap[X, Y any](ctx context.Context, xs Iterator[X], numWorkers int, fn func(ctx context.Context, dst *Y, x X) error) *ParallelMap[X, Y] {
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	dispatchCtx, stopDispatch := context.WithCancelCause(ctx)
+	m := &ParallelMap[X, Y]{
+		xs:           xs,
+		ctx:          ctx,
+		cancel:       cancel,
+		dispatchCtx:  dispatchCtx,
+		stopDispatch: stopDispatch,
+		order:        make(chan *pmSlot[Y], 2*numWorkers),
+		inflight:     make(map[int]context.CancelCauseFunc),
+		failed:       -1,
+	}
+	jobs := make(chan pmJob[X, Y])
+	m.wg.Add(1 + numWorkers)
+	go func() {
+		defer m.wg.Done()
+		defer close(m.order)
+		defer close(jobs)
+		m.dispatch(xs, jobs)
+	}()
+	for range numWorkers {
+		go func() {
+			defer m.wg.Done()
+			for job := range jobs {
+				err := fn(job.ctx, &job.slot.y, job.x)
+				m.finish(job.idx, err)
+				job.slot.err = err
+				close(job.slot.done)
+			}
+		}()
+	}
+	return m
+}
+
+// dispatch reads from xs and hands each element to a worker.
+// The slot is only added to order after a worker has accepted the job, so every slot in order will be completed.
+func (m *ParallelMap[X, Y]) dispatch(xs Iterator[X], jobs chan<- pmJob[X, Y]) {
+	for idx := 0; ; idx++ {
+		slot := &pmSlot[Y]{done: make(chan struct{})}
+		var x X
+		if err := NextUnit(m.dispatchCtx, xs, &x); err != nil {
+			if m.dispatchCtx.Err() != nil {
+				// an element failed, or the ParallelMap was cancelled.
+				return
+			}
+			slot.err = err
+			close(slot.done)
+			select {
+			case <-m.ctx.Done():
+			case m.order <- slot:
+			}
+			return
+		}
+		jobCtx, ok := m.start(idx)
+		if !ok {
+			return
+		}
+		select {
+		case <-m.dispatchCtx.Done():
+			m.finish(idx, nil)
+			return
+		case jobs <- pmJob[X, Y]{ctx: jobCtx, idx: idx, x: x, slot: slot}:
+		}
+		select {
+		case <-m.ctx.Done():
+			return
+		case m.order <- slot:
+		}
+	}
+}
+
+// start registers the element at idx as in-flight, and returns the context to map it with.
+// It returns false if an earlier element has already failed.
+func (m *ParallelMap[X, Y]) start(idx int) (context.Context, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failed >= 0 {
+		return nil, false
+	}
+	ctx, cancel := context.WithCancelCause(m.ctx)
+	m.inflight[idx] = cancel
+	return ctx, true
+}
+
+// finish removes the element at idx from the in-flight set.
+// If err is not nil, then the elements after idx are cancelled, and no more elements are read.
+func (m *ParallelMap[X, Y]) finish(idx int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inflight[idx](nil)
+	delete(m.inflight, idx)
+	if err == nil || (m.failed >= 0 && m.failed < idx) {
+		return
+	}
+	m.failed, m.failErr = idx, err
+	for i, cancel := range m.inflight {
+		if i > idx {
+			cancel(err)
+		}
+	}
+	m.stopDispatch(err)
+}
+
+func (m *ParallelMap[X, Y]) Next(ctx context.Context, dst []Y) (int, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	var n int
+	for n < len(dst) {
+		ok, err := m.next(ctx, &dst[n], n == 0)
+		if err != nil {
+			if n > 0 {
+				break
+			}
+			return 0, err
+		}
+		if !ok {
+			break
+		}
+		n++
+	}
+	return n, nil
+}
+
+// next reads the next result into dst.
+// If block is false, then next returns false instead of waiting for the result.
+func (m *ParallelMap[X, Y]) next(ctx context.Context, dst *Y, block bool) (bool, error) {
+	if m.head == nil {
+		if block {
+			select {
+			case <-ctx.Done():
+				return false, ctx.Err()
+			case m.head = <-m.order:
+			}
+		} else {
+			select {
+			case m.head = <-m.order:
+			default:
+				return false, nil
+			}
+		}
+		if m.head == nil {
+			// order has been closed without an error slot
+			m.mu.Lock()
+			m.err = m.failErr
+			m.mu.Unlock()
+			if m.err == nil {
+				m.err = context.Cause(m.ctx)
+			}
+			return false, m.err
+		}
+	}
+	if block {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-m.head.done:
+		}
+	} else {
+		select {
+		case <-m.head.done:
+		default:
+			return false, nil
+		}
+	}
+	slot := m.head
+	m.head = nil
+	if slot.err != nil {
+		m.err = slot.err
+		if cause := context.Cause(m.ctx); cause != nil {
+			m.err = cause
+		}
+		return false, m.err
+	}
+	*dst = slot.y
+	return true, nil
+}
+
+// Close cancels all in-flight work, waits for the background goroutines to exit, and then closes the input.
+func (m *ParallelMap[X, Y]) Close() error {
+	m.cancel(nil)
+	m.wg.Wait()
+	return Close(m.xs)
+}
@@ -45,3 +45,8 @@ func (pi *Peeker[T]) Peek(ctx context.Context, dst *T) error {
 	pi.cp(dst, pi.next.X)
 	return nil
 }
+
+// Close implements Closer
+func (pi *Peeker[T]) Close() error {
+	return Close(pi.x)
+}
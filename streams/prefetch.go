@@ -0,0 +1,146 @@
+package streams
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	_ Iterator[int] = &Prefetcher[int]{}
+	_ Peekable[int] = &Prefetcher[int]{}
+)
+
+// Prefetcher reads ahead from an Iterator on a background goroutine.
+//
+// Close must be called to release the background goroutine.
+type Prefetcher[T any] struct {
+	inner Iterator[T]
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+	wg     sync.WaitGroup
+	items  chan prefetchItem[T]
+
+	head *prefetchItem[T]
+	err  error
+}
+
+type prefetchItem[T any] struct {
+	x   T
+	err error
+}
+
+// Prefetch returns a Prefetcher which reads up to n elements ahead of the consumer.
+// The background goroutine is started on the first call to Next or Peek,
+// and stops when ctx is cancelled or the Prefetcher is closed.
+// Errors from it, including EOS, are returned after all the elements before them.
+func Prefetch[T any](ctx context.Context, it Iterator[T], n int) *Prefetcher[T] {
+	if n < 1 {
+		n = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &Prefetcher[T]{
+		inner:  it,
+		ctx:    ctx,
+		cancel: cancel,
+		// the background goroutine holds 1 element while it is blocked sending.
+		items: make(chan prefetchItem[T], n-1),
+	}
+}
+
+func (p *Prefetcher[T]) Next(ctx context.Context, dst []T) (int, error) {
+	var n int
+	for n < len(dst) {
+		ok, err := p.fill(ctx, n == 0)
+		if err != nil {
+			if n > 0 {
+				break
+			}
+			return 0, err
+		}
+		if !ok {
+			break
+		}
+		dst[n] = p.head.x
+		p.head = nil
+		n++
+	}
+	return n, nil
+}
+
+// Peek implements Peekable
+func (p *Prefetcher[T]) Peek(ctx context.Context, dst *T) error {
+	if _, err := p.fill(ctx, true); err != nil {
+		return err
+	}
+	*dst = p.head.x
+	return nil
+}
+
+// fill ensures that head is set.
+// If block is false, then fill returns false instead of waiting for an element.
+func (p *Prefetcher[T]) fill(ctx context.Context, block bool) (bool, error) {
+	if p.head != nil {
+		return true, nil
+	}
+	if p.err != nil {
+		return false, p.err
+	}
+	p.once.Do(func() {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			defer close(p.items)
+			p.readLoop()
+		}()
+	})
+	var item prefetchItem[T]
+	var ok bool
+	if block {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case item, ok = <-p.items:
+		}
+	} else {
+		select {
+		case item, ok = <-p.items:
+		default:
+			return false, nil
+		}
+	}
+	if !ok {
+		// the background goroutine only exits without an error when it is cancelled.
+		p.err = p.ctx.Err()
+		return false, p.err
+	}
+	if item.err != nil {
+		p.err = item.err
+		return false, p.err
+	}
+	p.head = &item
+	return true, nil
+}
+
+func (p *Prefetcher[T]) readLoop() {
+	for {
+		var item prefetchItem[T]
+		item.err = NextUnit(p.ctx, p.inner, &item.x)
+		select {
+		case <-p.ctx.Done():
+			return
+		case p.items <- item:
+		}
+		if item.err != nil {
+			return
+		}
+	}
+}
+
+// Close stops the background goroutine, waits for it to exit, and then closes the inner Iterator.
+func (p *Prefetcher[T]) Close() error {
+	p.cancel()
+	p.wg.Wait()
+	return Close(p.inner)
+}
@@ -0,0 +1,194 @@
+package streams
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+var (
+	_ Iterator[int]     = &Resilient[int]{}
+	_ Peekable[int]     = &Resilient[int]{}
+	_ Seeker[int]       = &Resilient[int]{}
+	_ Checkpointer[int] = &Resilient[int]{}
+)
+
+// ResilientConfig configures a Resilient
+type ResilientConfig struct {
+	// Timeout bounds each call to Next, Peek or Seek on the Iterator, including the Seek to resume it.
+	// It does not apply to open. If it is <= 0, then calls are not bounded.
+	// A call which times out fails with context.DeadlineExceeded, which Retry can choose to retry.
+	Timeout time.Duration
+	// Retry decides which errors cause the Iterator to be rebuilt.
+	// If it is nil, then no errors are retried.
+	Retry RetryPolicy
+}
+
+// Resilient wraps a sorted Iterator which can fail, such as one reading from remote storage.
+// When a call fails with an error that the RetryPolicy accepts, the Iterator is closed and rebuilt,
+// and then advanced to just after the last element emitted, so that no elements are repeated or missed.
+type Resilient[T any] struct {
+	open func(ctx context.Context) (Iterator[T], error)
+	cmp  func(a, b T) int
+	cfg  ResilientConfig
+
+	// ctx is passed to open, and is cancelled by Close.
+	ctx    context.Context
+	cancel context.CancelFunc
+	inner  Iterator[T]
+	// innerCancel cancels the context that inner was opened with.
+	innerCancel context.CancelFunc
+	pos         keyPos[T]
+}
+
+// NewResilient creates a Resilient.
+// open is called to create the Iterator, on the first call and after each retryable error.
+// The context passed to open lasts until the Iterator is discarded, or the Resilient is closed,
+// so the Iterator can hold on to it, as a network stream would.
+// The Iterators it creates must emit the same elements, sorted according to cmp, and must implement Seeker.
+func NewResilient[T any](open func(ctx context.Context) (Iterator[T], error), cmp func(a, b T) int, cfg ResilientConfig) *Resilient[T] {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Resilient[T]{
+		open:   open,
+		cmp:    cmp,
+		cfg:    cfg,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (r *Resilient[T]) Next(ctx context.Context, dst []T) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	var n int
+	if err := r.do(ctx, func(ctx context.Context) (err error) {
+		n, err = r.inner.Next(ctx, dst)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	for _, x := range dst[:n] {
+		r.pos.emitted(x, r.cmp)
+	}
+	return n, nil
+}
+
+// Peek implements Peekable.
+// The Iterators created by open must implement Peekable.
+func (r *Resilient[T]) Peek(ctx context.Context, dst *T) error {
+	return r.do(ctx, func(ctx context.Context) error {
+		p, ok := r.inner.(Peekable[T])
+		if !ok {
+			return noRetry{fmt.Errorf("streams: cannot peek %T, it does not implement Peekable", r.inner)}
+		}
+		return p.Peek(ctx, dst)
+	})
+}
+
+// Seek implements Seeker
+func (r *Resilient[T]) Seek(ctx context.Context, gteq T) error {
+	if err := r.do(ctx, func(ctx context.Context) error {
+		sk, ok := r.inner.(Seeker[T])
+		if !ok {
+			return noRetry{fmt.Errorf("streams: cannot seek %T, it does not implement Seeker", r.inner)}
+		}
+		return sk.Seek(ctx, gteq)
+	}); err != nil {
+		return err
+	}
+	r.pos.sought(gteq, r.cmp)
+	return nil
+}
+
+// Checkpoint implements Checkpointer
+func (r *Resilient[T]) Checkpoint(ctx context.Context) (Checkpoint[T], error) {
+	return r.pos.checkpoint(), nil
+}
+
+// do calls fn with the current Iterator, retrying according to the RetryPolicy.
+func (r *Resilient[T]) do(ctx context.Context, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := r.try(ctx, fn)
+		if nr, ok := err.(noRetry); ok {
+			return nr.error
+		}
+		if err == nil || IsEOS(err) || ctx.Err() != nil || r.cfg.Retry == nil {
+			return err
+		}
+		delay, ok := r.cfg.Retry(attempt, err)
+		if !ok {
+			return err
+		}
+		// the Iterator has already failed, so an error from Close is not interesting.
+		r.closeInner()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// try calls fn once, opening the Iterator first if necessary.
+// The timeout applies to the calls to the Iterator, but not to open.
+func (r *Resilient[T]) try(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.inner == nil {
+		openCtx, cancel := context.WithCancel(r.ctx)
+		it, err := r.open(openCtx)
+		if err != nil {
+			cancel()
+			return err
+		}
+		r.inner, r.innerCancel = it, cancel
+		if _, ok := it.(Seeker[T]); !ok && r.pos.last.Ok {
+			r.closeInner()
+			return noRetry{fmt.Errorf("streams: cannot resume %T, it does not implement Seeker", it)}
+		}
+		if err := r.withTimeout(ctx, func(ctx context.Context) error {
+			resumed, err := Resume(ctx, it, r.pos.checkpoint())
+			if err == nil {
+				r.inner = resumed
+			}
+			return err
+		}); err != nil {
+			r.closeInner()
+			return err
+		}
+	}
+	return r.withTimeout(ctx, fn)
+}
+
+// withTimeout calls fn with a context which is bounded by cfg.Timeout.
+func (r *Resilient[T]) withTimeout(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.cfg.Timeout)
+		defer cancel()
+	}
+	return fn(ctx)
+}
+
+// closeInner closes the current Iterator, if there is one, and cancels the context it was opened with.
+func (r *Resilient[T]) closeInner() error {
+	if r.inner == nil {
+		return nil
+	}
+	err := Close(r.inner)
+	r.innerCancel()
+	r.inner, r.innerCancel = nil, nil
+	return err
+}
+
+// Close closes the current Iterator, if there is one, and cancels the context passed to open.
+func (r *Resilient[T]) Close() error {
+	err := r.closeInner()
+	r.cancel()
+	return err
+}
+
+// noRetry wraps errors which are caused by misuse, rather than by the Iterator failing.
+// They are returned without consulting the RetryPolicy.
+type noRetry struct {
+	error
+}
@@ -19,7 +19,6 @@ func NewSeq[T any](seq iter.Seq[T]) *Seq[T] {
 }
 
 func (it *Seq[T]) Next(ctx context.Context, dst []T) (int, error) {
-	var n int
 	for i := range dst {
 		var ok bool
 		dst[i], ok = it.next()
@@ -31,7 +30,7 @@ func (it *Seq[T]) Next(ctx context.Context, dst []T) (int, error) {
 			}
 		}
 	}
-	return n, nil
+	return len(dst), nil
 }
 
 func (it *Seq[T]) Drop() {
@@ -84,3 +83,23 @@ func (it *SeqErr[T]) Close() error {
 	it.Drop()
 	return nil
 }
+
+// All returns an iter.Seq2 which yields the elements of it.
+// If it returns an error other than EOS, then the error is yielded with the zero value of T, and the sequence ends.
+func All[T any](ctx context.Context, it Iterator[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var x T
+		for {
+			if err := NextUnit(ctx, it, &x); err != nil {
+				if !IsEOS(err) {
+					var zero T
+					yield(zero, err)
+				}
+				return
+			}
+			if !yield(x, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package streams
+
+import (
+	"context"
+	"errors"
+)
+
+// Sink is the write side of a stream.
+// Sinks which hold resources should also implement Closer.
+type Sink[T any] interface {
+	// Write writes all of the elements in src, or returns an error.
+	// Write must not retain src after it returns.
+	Write(ctx context.Context, src []T) error
+	// Flush ensures that all elements passed to Write have been written to their final destination.
+	Flush(ctx context.Context) error
+}
+
+// CloseSink calls Close on s, if it implements Closer.
+// Otherwise CloseSink does nothing and returns nil.
+func CloseSink[T any](s Sink[T]) error {
+	if c, ok := s.(Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Copy reads all the elements from src, in batches, and writes them to dst.
+// Once src returns EOS, dst is flushed.
+// Copy returns the number of elements written.
+func Copy[T any](ctx context.Context, dst Sink[T], src Iterator[T]) (int, error) {
+	var total int
+	buf := make([]T, 64)
+	for {
+		n, err := src.Next(ctx, buf)
+		if err != nil {
+			if IsEOS(err) {
+				break
+			}
+			return total, err
+		}
+		if err := dst.Write(ctx, buf[:n]); err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, dst.Flush(ctx)
+}
+
+var _ Sink[int] = &SliceSink[int]{}
+
+// SliceSink is a Sink which appends elements to a slice.
+type SliceSink[T any] struct {
+	xs []T
+	cp func(dst *T, src T)
+}
+
+// NewSliceSink creates a SliceSink.
+// cp is used to copy elements into the slice, if it is nil, then assignment is used.
+func NewSliceSink[T any](cp func(dst *T, src T)) *SliceSink[T] {
+	if cp == nil {
+		cp = func(dst *T, src T) { *dst = src }
+	}
+	return &SliceSink[T]{cp: cp}
+}
+
+func (s *SliceSink[T]) Write(ctx context.Context, src []T) error {
+	for _, x := range src {
+		var y T
+		s.cp(&y, x)
+		s.xs = append(s.xs, y)
+	}
+	return nil
+}
+
+// Flush implements Sink. It does nothing.
+func (s *SliceSink[T]) Flush(ctx context.Context) error {
+	return nil
+}
+
+// Items returns the elements written so far.
+func (s *SliceSink[T]) Items() []T {
+	return s.xs
+}
+
+// Reset removes all of the elements.
+func (s *SliceSink[T]) Reset() {
+	clear(s.xs)
+	s.xs = s.xs[:0]
+}
+
+var _ Sink[int] = make(ChanSink[int])
+
+// ChanSink is a Sink which sends elements to a channel.
+type ChanSink[T any] chan<- T
+
+func (c ChanSink[T]) Write(ctx context.Context, src []T) error {
+	for _, x := range src {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case c <- x:
+		}
+	}
+	return nil
+}
+
+// Flush implements Sink. It does nothing.
+func (c ChanSink[T]) Flush(ctx context.Context) error {
+	return nil
+}
+
+// Close closes the channel.
+func (c ChanSink[T]) Close() error {
+	close(c)
+	return nil
+}
+
+var _ Sink[int] = &BufferedSink[int]{}
+
+// BufferedSink buffers elements, and writes them to another Sink in batches.
+type BufferedSink[T any] struct {
+	inner Sink[T]
+	cp    func(dst *T, src T)
+	buf   []T
+}
+
+// NewBufferedSink creates a BufferedSink which writes batches of size elements to inner.
+// cp is used to copy elements into the buffer, if it is nil, then assignment is used.
+func NewBufferedSink[T any](inner Sink[T], size int, cp func(dst *T, src T)) *BufferedSink[T] {
+	if size < 1 {
+		size = 1
+	}
+	if cp == nil {
+		cp = func(dst *T, src T) { *dst = src }
+	}
+	return &BufferedSink[T]{
+		inner: inner,
+		cp:    cp,
+		buf:   make([]T, 0, size),
+	}
+}
+
+func (s *BufferedSink[T]) Write(ctx context.Context, src []T) error {
+	for _, x := range src {
+		if len(s.buf) == cap(s.buf) {
+			if err := s.writeBuf(ctx); err != nil {
+				return err
+			}
+		}
+		s.buf = s.buf[:len(s.buf)+1]
+		s.cp(&s.buf[len(s.buf)-1], x)
+	}
+	return nil
+}
+
+// Flush writes all of the buffered elements to the inner Sink, and then flushes it.
+func (s *BufferedSink[T]) Flush(ctx context.Context) error {
+	if err := s.writeBuf(ctx); err != nil {
+		return err
+	}
+	return s.inner.Flush(ctx)
+}
+
+// Close flushes the BufferedSink and then closes the inner Sink.
+func (s *BufferedSink[T]) Close() error {
+	return errors.Join(s.Flush(context.Background()), CloseSink(s.inner))
+}
+
+func (s *BufferedSink[T]) writeBuf(ctx context.Context) error {
+	if len(s.buf) == 0 {
+		return nil
+	}
+	if err := s.inner.Write(ctx, s.buf); err != nil {
+		return err
+	}
+	s.buf = s.buf[:0]
+	return nil
+}
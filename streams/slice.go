@@ -1,6 +1,14 @@
 package streams
 
-import "context"
+import (
+	"context"
+	"slices"
+)
+
+var (
+	_ Peekable[int]     = &Slice[int]{}
+	_ Checkpointer[int] = &Slice[int]{}
+)
 
 type Slice[T any] struct {
 	xs  []T
@@ -39,12 +47,47 @@ func (it *Slice[T]) Peek(ctx context.Context, dst *T) error {
 // Skip implements Skipper
 func (it *Slice[T]) Skip(ctx context.Context, n int) error {
 	if it.pos+n > len(it.xs) {
+		it.pos = len(it.xs)
 		return EOS()
 	}
 	it.pos += n
 	return nil
 }
 
+// Checkpoint implements Checkpointer.
+// The Checkpoint contains the index of the next element.
+func (it *Slice[T]) Checkpoint(ctx context.Context) (Checkpoint[T], error) {
+	return Checkpoint[T]{skip: uint64(it.pos)}, nil
+}
+
 func (it *Slice[T]) Reset() {
 	it.pos = 0
 }
+
+var (
+	_ Peekable[int] = &SortedSlice[int]{}
+	_ Seeker[int]   = &SortedSlice[int]{}
+)
+
+// SortedSlice is a Slice which is sorted according to a compare function.
+// It implements Seeker using binary search.
+type SortedSlice[T any] struct {
+	Slice[T]
+	cmp func(a, b T) int
+}
+
+// NewSortedSlice returns a SortedSlice.
+// xs must already be sorted according to cmp.
+func NewSortedSlice[T any](xs []T, cmp func(a, b T) int, cp func(*T, T)) *SortedSlice[T] {
+	return &SortedSlice[T]{
+		Slice: *NewSlice(xs, cp),
+		cmp:   cmp,
+	}
+}
+
+// Seek implements Seeker
+func (it *SortedSlice[T]) Seek(ctx context.Context, gteq T) error {
+	i, _ := slices.BinarySearchFunc(it.xs[it.pos:], gteq, it.cmp)
+	it.pos += i
+	return nil
+}
@@ -0,0 +1,145 @@
+package streams
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"unsafe"
+)
+
+var (
+	_ Iterator[int] = &Sorter[int]{}
+	_ Peekable[int] = &Sorter[int]{}
+)
+
+// DefaultSortMemory is the memory budget used by Sort if none is specified.
+const DefaultSortMemory = 64 << 20
+
+// SortOptions configures Sort
+type SortOptions[T any] struct {
+	// Marshal appends the encoding of x to out.
+	Marshal func(out []byte, x T) []byte
+	// Unmarshal decodes data into dst.
+	// data must not be retained after Unmarshal returns.
+	Unmarshal func(data []byte, dst *T) error
+
+	// MemoryBudget is the number of bytes of elements to buffer before spilling a run to disk.
+	// If it is <= 0, then DefaultSortMemory is used.
+	MemoryBudget int
+	// SizeOf returns the number of bytes used by x, and is counted against MemoryBudget.
+	// If it is nil, then the size of T is used.
+	SizeOf func(x T) int
+	// Dir is the directory to create temporary files in.
+	// If it is empty, then the default directory for temporary files is used.
+	Dir string
+}
+
+// Sorter emits the elements of an Iterator in sorted order.
+// It is created by Sort, and Close must be called to remove its temporary files.
+type Sorter[T any] struct {
+	inner  Iterator[T]
+	merger *Merger[T]
+	files  []*os.File
+}
+
+// Sort reads all of the elements from it, and returns a Sorter which emits them in sorted order.
+// Elements are buffered in memory up to opts.MemoryBudget, then sorted and spilled to a temporary file as a run.
+// Runs are written with a FrameWriter, so each element is length prefixed, as in sbe.AppendLP.
+// The runs are merged when the Sorter is read, using a Merger.
+// The sort is stable.
+// opts.Marshal and opts.Unmarshal are required.
+func Sort[T any](ctx context.Context, it Iterator[T], cmp func(a, b T) int, opts SortOptions[T]) (*Sorter[T], error) {
+	if opts.Marshal == nil || opts.Unmarshal == nil {
+		return nil, fmt.Errorf("streams: Sort requires Marshal and Unmarshal")
+	}
+	if opts.MemoryBudget <= 0 {
+		opts.MemoryBudget = DefaultSortMemory
+	}
+	if opts.SizeOf == nil {
+		opts.SizeOf = func(x T) int { return int(unsafe.Sizeof(x)) }
+	}
+	s := &Sorter[T]{inner: it}
+	var run []T
+	var runSize int
+	buf := make([]T, 128)
+	for {
+		n, err := it.Next(ctx, buf)
+		if err != nil {
+			if IsEOS(err) {
+				break
+			}
+			s.Close()
+			return nil, err
+		}
+		for _, x := range buf[:n] {
+			run = append(run, x)
+			runSize += opts.SizeOf(x)
+			if runSize < opts.MemoryBudget {
+				continue
+			}
+			slices.SortStableFunc(run, cmp)
+			if err := s.spill(run, opts); err != nil {
+				s.Close()
+				return nil, err
+			}
+			clear(run)
+			run = run[:0]
+			runSize = 0
+		}
+		// the next call to it.Next must not reuse memory which is now in run.
+		clear(buf[:n])
+	}
+	inputs := make([]Peekable[T], 0, len(s.files)+1)
+	for _, f := range s.files {
+		inputs = append(inputs, NewPeeker[T](NewFrameReader(f, 0, opts.Unmarshal), nil))
+	}
+	// the last run stays in memory
+	slices.SortStableFunc(run, cmp)
+	inputs = append(inputs, NewSortedSlice(run, cmp, nil))
+	s.merger = NewMerger(inputs, cmp)
+	return s, nil
+}
+
+// spill writes a sorted run to a new temporary file, and rewinds the file so it can be read.
+func (s *Sorter[T]) spill(run []T, opts SortOptions[T]) error {
+	f, err := os.CreateTemp(opts.Dir, "streams-sort-")
+	if err != nil {
+		return err
+	}
+	s.files = append(s.files, f)
+	bw := bufio.NewWriter(f)
+	fw := NewFrameWriter(bw, 0, opts.Marshal)
+	for batch := range slices.Chunk(run, 128) {
+		if err := fw.Write(context.Background(), batch); err != nil {
+			return err
+		}
+	}
+	if err := fw.Flush(context.Background()); err != nil {
+		return err
+	}
+	_, err = f.Seek(0, io.SeekStart)
+	return err
+}
+
+func (s *Sorter[T]) Next(ctx context.Context, dst []T) (int, error) {
+	return s.merger.Next(ctx, dst)
+}
+
+// Peek implements Peekable
+func (s *Sorter[T]) Peek(ctx context.Context, dst *T) error {
+	return s.merger.Peek(ctx, dst)
+}
+
+// Close closes and removes all of the temporary files, and closes the Iterator that was sorted.
+func (s *Sorter[T]) Close() error {
+	errs := []error{Close(s.inner)}
+	for _, f := range s.files {
+		errs = append(errs, f.Close(), os.Remove(f.Name()))
+	}
+	s.files = nil
+	return errors.Join(errs...)
+}
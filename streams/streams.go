@@ -55,6 +55,12 @@ type Seeker[T any] interface {
 	Seek(ctx context.Context, gteq T) error
 }
 
+// Closer is implemented by Iterators which hold resources that must be released.
+// Combinators which implement Closer also close the Iterators that they wrap.
+type Closer interface {
+	Close() error
+}
+
 // Reader contains the Read method
 type Reader[T any] interface {
 	Read(ctx context.Context, dst []T) (int, error)
@@ -117,7 +123,7 @@ func ReadFull[T any](ctx context.Context, it Iterator[T], buf []T) (int, error)
 	for n < len(buf) {
 		if n2, err := it.Next(ctx, buf[n:]); err != nil {
 			return 0, err
-		} else if n < 1 {
+		} else if n2 < 1 {
 			return 0, fmt.Errorf("streams: incorrect iterator")
 		} else {
 			n += n2
@@ -202,3 +208,48 @@ func Skip[T any](ctx context.Context, it Iterator[T], n int) error {
 	}
 	return nil
 }
+
+// Seek advances it until all future elements are >= gteq, according to cmp.
+// If it implements Seeker, then Seek will be called instead.
+// otherwise Peek and Skip are called until an element >= gteq is found.
+func Seek[T any](ctx context.Context, it Peekable[T], gteq T, cmp func(a, b T) int) error {
+	if sk, ok := it.(Seeker[T]); ok {
+		return sk.Seek(ctx, gteq)
+	}
+	// fallback implementation
+	var x T
+	for {
+		if err := it.Peek(ctx, &x); err != nil {
+			if IsEOS(err) {
+				return nil
+			}
+			return err
+		}
+		if cmp(x, gteq) >= 0 {
+			return nil
+		}
+		if err := Skip(ctx, it, 1); err != nil {
+			return err
+		}
+	}
+}
+
+// Close calls Close on it, if it implements Closer.
+// Otherwise Close does nothing and returns nil.
+func Close[T any](it Iterator[T]) error {
+	if c, ok := it.(Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// closeAll calls Close on all of the Iterators, and returns all of the errors.
+func closeAll[T any, I Iterator[T]](its []I) error {
+	var errs []error
+	for _, it := range its {
+		if err := Close[T](it); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -1,15 +1,27 @@
 package streams
 
 import (
+	"bytes"
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
 	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.brendoncarroll.net/exp/maybe"
+	"go.brendoncarroll.net/exp/sbe"
 	"go.brendoncarroll.net/exp/slices2"
+	"golang.org/x/sync/errgroup"
 )
 
 func TestSlice(t *testing.T) {
@@ -49,6 +61,79 @@ func TestSeq(t *testing.T) {
 	}
 }
 
+func TestAll(t *testing.T) {
+	ctx := context.TODO()
+	var actual []int
+	for x, err := range All[int](ctx, NewSlice([]int{1, 2, 3}, nil)) {
+		require.NoError(t, err)
+		actual = append(actual, x)
+	}
+	require.Equal(t, []int{1, 2, 3}, actual)
+
+	failure := errors.New("failure")
+	seq := NewSeqErr(func(yield func(int, error) bool) {
+		_ = yield(1, nil) && yield(0, failure)
+	})
+	defer seq.Drop()
+	var errs []error
+	for _, err := range All[int](ctx, seq) {
+		errs = append(errs, err)
+	}
+	require.Equal(t, []error{nil, failure}, errs)
+}
+
+func TestToChan(t *testing.T) {
+	ctx := context.TODO()
+	xs := make([]int, 100)
+	for i := range xs {
+		xs[i] = i
+	}
+	t.Run("Drain", func(t *testing.T) {
+		ch, stop := ToChan[int](ctx, NewSlice(xs, nil), 10)
+		var actual []int
+		for x := range ch {
+			actual = append(actual, x)
+		}
+		require.NoError(t, stop())
+		require.Equal(t, xs, actual)
+	})
+	t.Run("StopEarly", func(t *testing.T) {
+		ch, stop := ToChan[int](ctx, NewSlice(xs, nil), 0)
+		require.Equal(t, 0, <-ch)
+		require.NoError(t, stop())
+		require.NoError(t, stop())
+	})
+	t.Run("Error", func(t *testing.T) {
+		failure := errors.New("failure")
+		seq := NewSeqErr(func(yield func(int, error) bool) {
+			_ = yield(1, nil) && yield(0, failure)
+		})
+		defer seq.Drop()
+		ch, stop := ToChan[int](ctx, seq, 10)
+		for range ch {
+		}
+		require.ErrorIs(t, stop(), failure)
+	})
+}
+
+func TestReadFull(t *testing.T) {
+	ctx := context.TODO()
+	// Chan emits 1 element per call, so ReadFull has to call Next several times.
+	c := make(chan int, 5)
+	for i := range 5 {
+		c <- i
+	}
+	close(c)
+	buf := make([]int, 3)
+	n, err := ReadFull[int](ctx, Chan[int](c), buf)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, []int{0, 1, 2}, buf)
+
+	_, err = ReadFull[int](ctx, Chan[int](c), buf)
+	require.ErrorIs(t, err, EOS())
+}
+
 func TestMerge(t *testing.T) {
 	type testCase struct {
 		Ins [][]int
@@ -97,6 +182,1106 @@ func TestMerge(t *testing.T) {
 	}
 }
 
+func TestResolvingMerger(t *testing.T) {
+	type entry struct {
+		Key, Value int
+	}
+	ctx := context.TODO()
+	ins := slices2.Map([][]entry{
+		{{1, 10}, {3, 10}, {5, 10}},
+		{{1, 20}, {2, 20}, {5, 20}},
+		{{0, 30}, {5, 30}},
+	}, func(x []entry) Peekable[entry] {
+		return NewSlice(x, nil)
+	})
+	m := NewResolvingMerger(ins, func(a, b entry) int {
+		return cmp.Compare(a.Key, b.Key)
+	}, func(a, b entry) entry {
+		return entry{Key: a.Key, Value: a.Value + b.Value}
+	})
+	first, err := Peek(ctx, m)
+	require.NoError(t, err)
+	require.Equal(t, entry{0, 30}, first)
+	actual, err := Collect(ctx, m, 100)
+	require.NoError(t, err)
+	require.Equal(t, []entry{{0, 30}, {1, 30}, {2, 20}, {3, 10}, {5, 60}}, actual)
+}
+
+func TestResolvingMergerError(t *testing.T) {
+	type entry struct {
+		Key, Value int
+	}
+	ctx := context.TODO()
+	errTransient := errors.New("transient")
+	newest := NewSlice([]entry{{1, 100}}, nil)
+	older := NewSlice([]entry{{1, 1}, {2, 2}}, nil)
+	m := NewResolvingMerger([]Peekable[entry]{
+		newest,
+		&peekErrOnce[entry]{errOnce: errOnce[entry]{Iterator: older, err: errTransient}, p: older},
+	}, func(a, b entry) int {
+		return cmp.Compare(a.Key, b.Key)
+	}, func(a, _ entry) entry { return a })
+
+	var dst entry
+	require.ErrorIs(t, NextUnit(ctx, m, &dst), errTransient)
+	first, err := Peek(ctx, m)
+	require.NoError(t, err)
+	require.Equal(t, entry{1, 100}, first)
+	actual, err := Collect(ctx, m, 100)
+	require.NoError(t, err)
+	require.Equal(t, []entry{{1, 100}, {2, 2}}, actual)
+}
+
+// peekErrOnce is errOnce for a Peekable; Peek is passed through.
+type peekErrOnce[T any] struct {
+	errOnce[T]
+	p Peekable[T]
+}
+
+func (it *peekErrOnce[T]) Peek(ctx context.Context, dst *T) error {
+	return it.p.Peek(ctx, dst)
+}
+
+func TestLayered(t *testing.T) {
+	type entry struct {
+		Key   int
+		Value string
+	}
+	ctx := context.TODO()
+	cmpKey := func(a, b entry) int { return cmp.Compare(a.Key, b.Key) }
+	newLayered := func() *Layered[entry] {
+		return NewLayered(slices2.Map([][]entry{
+			// newest
+			{{1, "c"}, {3, ""}, {6, "c"}},
+			{{0, "b"}, {1, "b"}, {3, "b"}, {4, ""}},
+			// oldest
+			{{1, "a"}, {2, "a"}, {4, "a"}, {5, "a"}},
+		}, func(x []entry) Peekable[entry] {
+			return NewSortedSlice(x, cmpKey, nil)
+		}), cmpKey, func(x entry) bool { return x.Value == "" })
+	}
+	actual, err := Collect[entry](ctx, newLayered(), 100)
+	require.NoError(t, err)
+	require.Equal(t, []entry{{0, "b"}, {1, "c"}, {2, "a"}, {5, "a"}, {6, "c"}}, actual)
+
+	l := newLayered()
+	require.NoError(t, l.Seek(ctx, entry{Key: 3}))
+	first, err := Peek[entry](ctx, l)
+	require.NoError(t, err)
+	require.Equal(t, entry{5, "a"}, first)
+	actual, err = Collect[entry](ctx, l, 100)
+	require.NoError(t, err)
+	require.Equal(t, []entry{{5, "a"}, {6, "c"}}, actual)
+}
+
+func TestSeek(t *testing.T) {
+	ctx := context.TODO()
+	sorted := func(xs ...int) *SortedSlice[int] {
+		return NewSortedSlice(xs, cmp.Compare[int], nil)
+	}
+	t.Run("SortedSlice", func(t *testing.T) {
+		it := sorted(0, 2, 4, 6, 8)
+		require.NoError(t, it.Seek(ctx, 3))
+		actual, err := Collect(ctx, it, 10)
+		require.NoError(t, err)
+		require.Equal(t, []int{4, 6, 8}, actual)
+		require.NoError(t, it.Seek(ctx, 100))
+		require.ErrorIs(t, NextUnit(ctx, it, new(int)), EOS())
+	})
+	t.Run("Fallback", func(t *testing.T) {
+		it := NewSlice([]int{0, 2, 4, 6, 8}, nil)
+		require.NoError(t, Seek(ctx, it, 5, cmp.Compare[int]))
+		actual, err := Collect(ctx, it, 10)
+		require.NoError(t, err)
+		require.Equal(t, []int{6, 8}, actual)
+	})
+	t.Run("Merger", func(t *testing.T) {
+		m := NewMerger([]Peekable[int]{
+			sorted(0, 3, 6, 9),
+			NewSlice([]int{1, 4, 7}, nil),
+			sorted(2, 5, 8),
+		}, cmp.Compare[int])
+		x, err := Next(ctx, m)
+		require.NoError(t, err)
+		require.Equal(t, 0, x)
+		require.NoError(t, m.Seek(ctx, 5))
+		actual, err := Collect(ctx, m, 10)
+		require.NoError(t, err)
+		require.Equal(t, []int{5, 6, 7, 8, 9}, actual)
+	})
+	t.Run("OJoiner", func(t *testing.T) {
+		j := NewOJoiner(sorted(1, 2, 3, 5), NewSlice([]int{2, 3, 4, 5}, nil), cmp.Compare[int])
+		require.NoError(t, j.Seek(ctx, OJoined[int, int]{Left: maybe.Just(3)}))
+		actual, err := Collect(ctx, j, 10)
+		require.NoError(t, err)
+		require.Equal(t, []OJoined[int, int]{both(3), rightOnly(4), both(5)}, zeroNothings(actual))
+	})
+	t.Run("Filter", func(t *testing.T) {
+		it := NewFilter[int](sorted(0, 1, 2, 3, 4, 5, 6), func(x int) bool { return x%2 == 1 })
+		require.Implements(t, (*Seeker[int])(nil), it)
+		require.NoError(t, it.(Seeker[int]).Seek(ctx, 2))
+		actual, err := Collect(ctx, it, 10)
+		require.NoError(t, err)
+		require.Equal(t, []int{3, 5}, actual)
+	})
+}
+
+func TestMutatorSeeker(t *testing.T) {
+	ctx := context.TODO()
+	var it Iterator[int] = NewMutator[int](NewSortedSlice([]int{1, 2}, cmp.Compare[int], nil), func(*int) bool { return true })
+	_, ok := it.(Seeker[int])
+	require.False(t, ok)
+
+	m := NewSeekMutator[int](NewSortedSlice([]int{1, 2, 3, 4}, cmp.Compare[int], nil), func(x *int) bool {
+		*x *= 10
+		return *x != 30
+	})
+	require.NoError(t, m.Seek(ctx, 2))
+	actual, err := Collect[int](ctx, m, 10)
+	require.NoError(t, err)
+	require.Equal(t, []int{20, 40}, actual)
+}
+
+func TestParallelMap(t *testing.T) {
+	ctx := context.TODO()
+	xs := make([]int, 1000)
+	for i := range xs {
+		xs[i] = i
+	}
+	t.Run("Order", func(t *testing.T) {
+		m := NewParallelMap(ctx, NewSlice(xs, nil), 8, func(ctx context.Context, dst *string, x int) error {
+			time.Sleep(time.Duration(x%7) * time.Microsecond)
+			*dst = strconv.Itoa(x)
+			return nil
+		})
+		defer m.Close()
+		actual, err := Collect(ctx, m, len(xs))
+		require.NoError(t, err)
+		require.Equal(t, slices2.Map(xs, strconv.Itoa), actual)
+		require.ErrorIs(t, NextUnit(ctx, m, new(string)), EOS())
+	})
+	t.Run("Error", func(t *testing.T) {
+		failure := errors.New("failure")
+		m := NewParallelMap(ctx, NewSlice(xs, nil), 8, func(ctx context.Context, dst *int, x int) error {
+			if x == 500 {
+				return failure
+			}
+			*dst = x
+			return ctx.Err()
+		})
+		defer m.Close()
+		actual, err := Collect(ctx, m, len(xs))
+		require.ErrorIs(t, err, failure)
+		require.Equal(t, xs[:len(actual)], actual)
+		require.LessOrEqual(t, len(actual), 500)
+	})
+	t.Run("ErrorAfterEarlierElements", func(t *testing.T) {
+		failure := errors.New("failure")
+		for range 10 {
+			m := NewParallelMap(ctx, NewSlice(xs[:100], nil), 8, func(ctx context.Context, dst *int, x int) error {
+				if x == 50 {
+					return failure
+				}
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Duration(x%3) * time.Millisecond):
+				}
+				*dst = x
+				return nil
+			})
+			actual, err := Collect(ctx, m, 100)
+			require.ErrorIs(t, err, failure)
+			require.Equal(t, xs[:50], actual)
+			require.NoError(t, m.Close())
+		}
+	})
+}
+
+func TestPrefetch(t *testing.T) {
+	ctx := context.TODO()
+	t.Run("Slice", func(t *testing.T) {
+		p := Prefetch(ctx, NewSlice([]int{0, 1, 2, 3, 4, 5, 6}, nil), 3)
+		defer p.Close()
+		for range 3 {
+			x, err := Peek[int](ctx, p)
+			require.NoError(t, err)
+			require.Equal(t, 0, x)
+		}
+		actual, err := Collect(ctx, p, 10)
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6}, actual)
+		require.ErrorIs(t, NextUnit(ctx, p, new(int)), EOS())
+	})
+	t.Run("Error", func(t *testing.T) {
+		failure := errors.New("failure")
+		seq := NewSeqErr(func(yield func(int, error) bool) {
+			_ = yield(1, nil) && yield(2, nil) && yield(0, failure)
+		})
+		defer seq.Drop()
+		p := Prefetch[int](ctx, seq, 10)
+		defer p.Close()
+		actual, err := Collect(ctx, p, 10)
+		require.ErrorIs(t, err, failure)
+		require.Equal(t, []int{1, 2}, actual)
+		require.ErrorIs(t, NextUnit(ctx, p, new(int)), failure)
+	})
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		p := Prefetch[int](ctx, Chan[int](make(chan int)), 10)
+		defer p.Close()
+		cancel()
+		require.ErrorIs(t, NextUnit(ctx, p, new(int)), context.Canceled)
+	})
+}
+
+func TestTee(t *testing.T) {
+	ctx := context.TODO()
+	xs := make([]int, 1000)
+	for i := range xs {
+		xs[i] = i
+	}
+	t.Run("Concurrent", func(t *testing.T) {
+		branches := Tee[int](NewSlice(xs, nil), 3, 10, nil)
+		outs := make([][]int, len(branches))
+		eg := errgroup.Group{}
+		for i, b := range branches {
+			eg.Go(func() error {
+				var err error
+				outs[i], err = Collect[int](ctx, b, len(xs))
+				return err
+			})
+		}
+		require.NoError(t, eg.Wait())
+		for _, out := range outs {
+			require.Equal(t, xs, out)
+		}
+	})
+	t.Run("Bounded", func(t *testing.T) {
+		branches := Tee[int](NewSlice(xs, nil), 2, 10, nil)
+		buf := make([]int, 100)
+		n, err := ReadFull[int](ctx, branches[0], buf[:10])
+		require.NoError(t, err)
+		require.Equal(t, xs[:n], buf[:n])
+
+		ctx2, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = branches[0].Next(ctx2, buf)
+		require.ErrorIs(t, err, context.Canceled)
+
+		require.NoError(t, branches[1].Close())
+		actual, err := Collect[int](ctx, branches[0], len(xs))
+		require.NoError(t, err)
+		require.Equal(t, xs[10:], actual)
+	})
+}
+
+func TestWindows(t *testing.T) {
+	ctx := context.TODO()
+	xs := []int{0, 1, 2, 3, 4, 5, 6}
+	type testCase struct {
+		Size, Step int
+		Out        [][]int
+	}
+	tcs := []testCase{
+		{Size: 3, Step: 3, Out: [][]int{{0, 1, 2}, {3, 4, 5}, {6}}},
+		{Size: 3, Step: 2, Out: [][]int{{0, 1, 2}, {2, 3, 4}, {4, 5, 6}}},
+		{Size: 2, Step: 3, Out: [][]int{{0, 1}, {3, 4}, {6}}},
+		{Size: 10, Step: 1, Out: [][]int{{0, 1, 2, 3, 4, 5, 6}}},
+	}
+	for i, tc := range tcs {
+		t.Run(fmt.Sprintf("Sliding%d", i), func(t *testing.T) {
+			it := NewSliding(NewSlice(xs, nil), tc.Size, tc.Step)
+			actual, err := Collect(ctx, it, 10)
+			require.NoError(t, err)
+			require.Equal(t, tc.Out, actual)
+		})
+	}
+	t.Run("SkipError", func(t *testing.T) {
+		errTransient := errors.New("transient")
+		inner := &errOnce[int]{Iterator: NewSlice([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, nil), n: 3, err: errTransient}
+		it := NewSliding[int](inner, 2, 4)
+		w, err := Next[[]int](ctx, it)
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1}, w)
+		// the error happens part way through the gap
+		_, err = Next[[]int](ctx, it)
+		require.ErrorIs(t, err, errTransient)
+		actual, err := Collect(ctx, it, 10)
+		require.NoError(t, err)
+		require.Equal(t, [][]int{{4, 5}, {8, 9}}, actual)
+	})
+	t.Run("Key", func(t *testing.T) {
+		it := NewKeyWindows(NewSlice([]int{1, 3, 5, 2, 4, 7, 9, 6}, nil), func(x int) int { return x % 2 })
+		actual, err := Collect(ctx, it, 10)
+		require.NoError(t, err)
+		require.Equal(t, [][]int{{1, 3, 5}, {2, 4}, {7, 9}, {6}}, actual)
+	})
+	t.Run("Session", func(t *testing.T) {
+		ts := func(x int) time.Time { return time.Unix(int64(x), 0) }
+		it := NewSessionWindows(NewSlice([]int{0, 1, 2, 10, 11, 30}, nil), ts, 5*time.Second)
+		actual, err := Collect(ctx, it, 10)
+		require.NoError(t, err)
+		require.Equal(t, [][]int{{0, 1, 2}, {10, 11}, {30}}, actual)
+	})
+}
+
+func TestGroupBy(t *testing.T) {
+	ctx := context.TODO()
+	xs := []int{10, 11, 12, 20, 30, 31}
+	eq := func(a, b int) bool { return a/10 == b/10 }
+	t.Run("Groups", func(t *testing.T) {
+		gb := NewGroupBy[int](NewSlice(xs, nil), eq)
+		var firsts []int
+		var actual [][]int
+		for i := 0; ; i++ {
+			g, err := Next[*Group[int]](ctx, gb)
+			if IsEOS(err) {
+				break
+			}
+			require.NoError(t, err)
+			firsts = append(firsts, g.First())
+			if i == 0 {
+				// only read part of the first group
+				x, err := Next[int](ctx, g)
+				require.NoError(t, err)
+				actual = append(actual, []int{x})
+				continue
+			}
+			ys, err := Collect[int](ctx, g, 10)
+			require.NoError(t, err)
+			actual = append(actual, ys)
+		}
+		require.Equal(t, []int{10, 20, 30}, firsts)
+		require.Equal(t, [][]int{{10}, {20}, {30, 31}}, actual)
+	})
+	t.Run("Slices", func(t *testing.T) {
+		actual, err := Collect(ctx, NewGroupSlices(NewSlice(xs, nil), eq), 10)
+		require.NoError(t, err)
+		require.Equal(t, [][]int{{10, 11, 12}, {20}, {30, 31}}, actual)
+	})
+	t.Run("Fold", func(t *testing.T) {
+		type count struct{ Key, N int }
+		it := NewGroupFold[int](NewSlice(xs, nil), eq, func(x int) count {
+			return count{Key: x / 10, N: 1}
+		}, func(acc count, x int) count {
+			acc.N++
+			return acc
+		})
+		actual, err := Collect(ctx, it, 10)
+		require.NoError(t, err)
+		require.Equal(t, []count{{1, 3}, {2, 1}, {3, 2}}, actual)
+	})
+	t.Run("Reduce", func(t *testing.T) {
+		it := NewGroupReduce[int](NewSlice(xs, nil), eq, func(a, b int) int { return a + b })
+		actual, err := Collect(ctx, it, 10)
+		require.NoError(t, err)
+		require.Equal(t, []int{33, 20, 61}, actual)
+	})
+}
+
+func TestSort(t *testing.T) {
+	ctx := context.TODO()
+	for _, budget := range []int{8 * 50, 8 * 1000, 8 * 10_000} {
+		dir := t.TempDir()
+		rng := rand.New(rand.NewSource(0))
+		xs := make([]uint64, 5000)
+		for i := range xs {
+			xs[i] = uint64(rng.Intn(1000))
+		}
+		s, err := Sort[uint64](ctx, NewSlice(xs, nil), cmp.Compare[uint64], SortOptions[uint64]{
+			Marshal: sbe.AppendUint64,
+			Unmarshal: func(data []byte, dst *uint64) error {
+				x, _, err := sbe.ReadUint64(data)
+				*dst = x
+				return err
+			},
+			MemoryBudget: budget,
+			Dir:          dir,
+		})
+		require.NoError(t, err)
+		actual, err := Collect[uint64](ctx, s, len(xs))
+		require.NoError(t, err)
+		slices.Sort(xs)
+		require.Equal(t, xs, actual)
+
+		require.NoError(t, s.Close())
+		ents, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, ents)
+	}
+
+	_, err := Sort[uint64](ctx, NewSlice([]uint64{2, 1}, nil), cmp.Compare[uint64], SortOptions[uint64]{MemoryBudget: 8})
+	require.Error(t, err)
+}
+
+func TestDiffer(t *testing.T) {
+	ctx := context.TODO()
+	type entry struct {
+		Key, Value int
+	}
+	cmpKey := func(a, b entry) int { return cmp.Compare(a.Key, b.Key) }
+	eq := func(a, b entry) bool { return a == b }
+	old := []entry{{0, 0}, {1, 1}, {2, 2}, {3, 3}, {5, 5}, {6, 6}}
+	new := []entry{{1, 1}, {2, 20}, {3, 3}, {4, 4}, {5, 5}, {6, 60}}
+
+	d := NewDiffer[entry](NewSortedSlice(old, cmpKey, nil), NewSortedSlice(new, cmpKey, nil), cmpKey, eq)
+	actual, err := Collect(ctx, d, 10)
+	require.NoError(t, err)
+	require.Equal(t, []Delta[entry]{
+		{Op: Removed, Old: entry{0, 0}},
+		{Op: Changed, Old: entry{2, 2}, New: entry{2, 20}},
+		{Op: Added, New: entry{4, 4}},
+		{Op: Changed, Old: entry{6, 6}, New: entry{6, 60}},
+	}, actual)
+
+	d = NewDiffer[entry](NewSortedSlice(old, cmpKey, nil), NewSlice(new, nil), cmpKey, eq)
+	require.NoError(t, d.Seek(ctx, entry{Key: 5}))
+	actual, err = Collect(ctx, d, 10)
+	require.NoError(t, err)
+	require.Equal(t, []Delta[entry]{
+		{Op: Changed, Old: entry{6, 6}, New: entry{6, 60}},
+	}, actual)
+}
+
+func TestClose(t *testing.T) {
+	ctx := context.TODO()
+	newInputs := func(n int) ([]*closeTracker[int], []Peekable[int]) {
+		trackers := make([]*closeTracker[int], n)
+		ps := make([]Peekable[int], n)
+		for i := range trackers {
+			trackers[i] = &closeTracker[int]{Slice: NewSlice([]int{i}, nil)}
+			ps[i] = trackers[i]
+		}
+		return trackers, ps
+	}
+	requireClosed := func(t *testing.T, trackers []*closeTracker[int]) {
+		for i, tr := range trackers {
+			require.Equal(t, 1, tr.closed, "input %d", i)
+		}
+	}
+	t.Run("Concat", func(t *testing.T) {
+		trackers, ins := newInputs(3)
+		it := Concat(trackers[0], Iterator[int](ins[1]), ins[2])
+		x, err := Next(ctx, it)
+		require.NoError(t, err)
+		require.Equal(t, 0, x)
+		x, err = Next(ctx, it)
+		require.NoError(t, err)
+		require.Equal(t, 1, x)
+		// the first input was exhausted
+		require.Equal(t, []int{1, 0, 0}, slices2.Map(trackers, func(tr *closeTracker[int]) int { return tr.closed }))
+		require.NoError(t, Close(it))
+		requireClosed(t, trackers)
+	})
+	t.Run("FlatMap", func(t *testing.T) {
+		trackers, _ := newInputs(3)
+		it := FlatMap(NewSlice([]int{0, 1, 2}, nil), func(ctx context.Context, i int) (Iterator[int], error) {
+			return trackers[i], nil
+		})
+		x, err := Next(ctx, it)
+		require.NoError(t, err)
+		require.Equal(t, 0, x)
+		x, err = Next(ctx, it)
+		require.NoError(t, err)
+		require.Equal(t, 1, x)
+		// the first inner Iterator was exhausted, and the third has not been opened.
+		require.Equal(t, []int{1, 0, 0}, slices2.Map(trackers, func(tr *closeTracker[int]) int { return tr.closed }))
+		require.NoError(t, Close(it))
+		require.Equal(t, []int{1, 1, 0}, slices2.Map(trackers, func(tr *closeTracker[int]) int { return tr.closed }))
+	})
+	t.Run("Merger", func(t *testing.T) {
+		trackers, ins := newInputs(3)
+		require.NoError(t, Close[int](NewMerger(ins, cmp.Compare[int])))
+		requireClosed(t, trackers)
+	})
+	t.Run("OJoiner", func(t *testing.T) {
+		trackers, ins := newInputs(2)
+		require.NoError(t, Close[OJoined[int, int]](NewOJoiner(ins[0], ins[1], cmp.Compare[int])))
+		requireClosed(t, trackers)
+	})
+	t.Run("Wrappers", func(t *testing.T) {
+		trackers, ins := newInputs(4)
+		its := []Iterator[int]{
+			NewFilter[int](ins[0], func(int) bool { return true }),
+			NewMutator[int](ins[1], func(*int) bool { return true }),
+			NewMap(Iterator[int](ins[2]), func(y *int, x int) { *y = x }),
+			&Peeker[int]{x: ins[3]},
+		}
+		for _, it := range its {
+			require.NoError(t, Close(it))
+		}
+		requireClosed(t, trackers)
+	})
+	t.Run("Batcher", func(t *testing.T) {
+		trackers, ins := newInputs(1)
+		b := NewBatcher[int](ins[0], 10, time.Hour)
+		_, err := Next[[]int](ctx, b)
+		require.NoError(t, err)
+		require.NoError(t, Close[[]int](b))
+		requireClosed(t, trackers)
+	})
+}
+
+// closeTracker counts the calls to Close
+type closeTracker[T any] struct {
+	*Slice[T]
+	closed int
+}
+
+func (ct *closeTracker[T]) Close() error {
+	ct.closed++
+	return nil
+}
+
+func TestCopy(t *testing.T) {
+	ctx := context.TODO()
+	xs := make([]int, 1000)
+	for i := range xs {
+		xs[i] = i
+	}
+	t.Run("Slice", func(t *testing.T) {
+		dst := NewSliceSink[int](nil)
+		n, err := Copy[int](ctx, dst, NewSlice(xs, nil))
+		require.NoError(t, err)
+		require.Equal(t, len(xs), n)
+		require.Equal(t, xs, dst.Items())
+	})
+	t.Run("Buffered", func(t *testing.T) {
+		inner := &batchRecorder{}
+		dst := NewBufferedSink[int](inner, 300, nil)
+		_, err := Copy[int](ctx, dst, NewSlice(xs, nil))
+		require.NoError(t, err)
+		require.Equal(t, []int{300, 300, 300, 100}, inner.sizes)
+		require.Equal(t, xs, inner.Items())
+	})
+	t.Run("Chan", func(t *testing.T) {
+		ch := make(chan int)
+		eg := errgroup.Group{}
+		eg.Go(func() error {
+			dst := ChanSink[int](ch)
+			defer dst.Close()
+			_, err := Copy[int](ctx, dst, NewSlice(xs, nil))
+			return err
+		})
+		actual, err := Collect[int](ctx, Chan[int](ch), len(xs))
+		require.NoError(t, err)
+		require.NoError(t, eg.Wait())
+		require.Equal(t, xs, actual)
+	})
+}
+
+// batchRecorder is a Sink which records the size of each write.
+type batchRecorder struct {
+	SliceSink[int]
+	sizes []int
+}
+
+func (br *batchRecorder) Write(ctx context.Context, src []int) error {
+	br.sizes = append(br.sizes, len(src))
+	br.xs = append(br.xs, src...)
+	return nil
+}
+
+func TestFrames(t *testing.T) {
+	ctx := context.TODO()
+	unmarshal := func(data []byte, dst *string) error {
+		*dst = string(data)
+		return nil
+	}
+	marshal := func(out []byte, x string) []byte {
+		return append(out, x...)
+	}
+	xs := []string{"", "a", "hello world", strings.Repeat("x", 300)}
+
+	var buf bytes.Buffer
+	n, err := Copy[string](ctx, NewFrameWriter(&buf, 300, marshal), NewSlice(xs, nil))
+	require.NoError(t, err)
+	require.Equal(t, len(xs), n)
+	data := buf.Bytes()
+
+	actual, err := Collect[string](ctx, NewFrameReader(bytes.NewReader(data), 300, unmarshal), 10)
+	require.NoError(t, err)
+	require.Equal(t, xs, actual)
+
+	t.Run("Truncated", func(t *testing.T) {
+		fr := NewFrameReader(bytes.NewReader(data[:len(data)-1]), 300, unmarshal)
+		actual, err := Collect[string](ctx, fr, 10)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		require.Equal(t, xs[:3], actual)
+		require.ErrorIs(t, NextUnit(ctx, fr, new(string)), io.ErrUnexpectedEOF)
+	})
+	t.Run("TooLarge", func(t *testing.T) {
+		_, err := Collect[string](ctx, NewFrameReader(bytes.NewReader(data), 299, unmarshal), 10)
+		require.ErrorContains(t, err, "exceeds max size")
+		_, err = Copy[string](ctx, NewFrameWriter(io.Discard, 299, marshal), NewSlice(xs, nil))
+		require.ErrorContains(t, err, "exceeds max size")
+	})
+	t.Run("CorruptLength", func(t *testing.T) {
+		// no limit, and a length which cannot be an int
+		corrupt := sbe.AppendUVarint(nil, math.MaxUint64)
+		_, err := Collect[string](ctx, NewFrameReader(bytes.NewReader(corrupt), 0, unmarshal), 10)
+		require.ErrorContains(t, err, "too large")
+		// no limit, and a huge length with only a few bytes of data
+		corrupt = append(sbe.AppendUVarint(nil, 1<<50), "abc"...)
+		_, err = Collect[string](ctx, NewFrameReader(bytes.NewReader(corrupt), 0, unmarshal), 10)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
+
+func TestTake(t *testing.T) {
+	ctx := context.TODO()
+	xs := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	type testCase struct {
+		Name string
+		It   Iterator[int]
+		Out  []int
+	}
+	tcs := []testCase{
+		{Name: "Take", It: Take[int](NewSlice(xs, nil), 3), Out: []int{0, 1, 2}},
+		{Name: "TakeAll", It: Take[int](NewSlice(xs, nil), 100), Out: xs},
+		{Name: "TakeWhile", It: TakeWhile[int](NewSlice(xs, nil), func(x int) bool { return x < 4 }), Out: []int{0, 1, 2, 3}},
+		{Name: "SkipWhile", It: SkipWhile[int](NewSlice(xs, nil), func(x int) bool { return x < 7 }), Out: []int{7, 8, 9}},
+		{Name: "StepBy", It: StepBy[int](NewSlice(xs, nil), 4), Out: []int{0, 4, 8}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.Name, func(t *testing.T) {
+			require.Implements(t, (*Peekable[int])(nil), tc.It)
+			actual, err := Collect(ctx, tc.It, 100)
+			require.NoError(t, err)
+			require.Equal(t, tc.Out, actual)
+		})
+	}
+	t.Run("Page", func(t *testing.T) {
+		it := Take[int](NewSortedSlice(xs, cmp.Compare[int], nil), 3)
+		require.NoError(t, it.(Seeker[int]).Seek(ctx, 5))
+		actual, err := Collect(ctx, it, 100)
+		require.NoError(t, err)
+		require.Equal(t, []int{5, 6, 7}, actual)
+	})
+	t.Run("NotPeekable", func(t *testing.T) {
+		it := Take(NewFilter[int](NewSlice(xs, nil), func(int) bool { return true }), 3)
+		_, ok := it.(Peekable[int])
+		require.False(t, ok)
+	})
+}
+
+func TestInstrument(t *testing.T) {
+	ctx := context.TODO()
+	xs := make([]int, 100)
+	for i := range xs {
+		xs[i] = i
+	}
+	var stats Stats
+	rec := &hookRecorder{Hooks: &stats}
+	it := Instrument[int](NewSortedSlice(xs, cmp.Compare[int], nil), rec)
+	_, err := Peek(ctx, it.(Peekable[int]))
+	require.NoError(t, err)
+	require.NoError(t, it.(Seeker[int]).Seek(ctx, 50))
+	buf := make([]int, 10)
+	for {
+		if _, err := it.Next(ctx, buf); err != nil {
+			require.ErrorIs(t, err, EOS())
+			break
+		}
+	}
+	require.Equal(t, int64(50), stats.Elements.Load())
+	require.Equal(t, int64(5), stats.Batches.Load())
+	require.Equal(t, int64(0), stats.Errors.Load())
+	require.Equal(t, []Op{OpPeek, OpSeek, OpNext, OpNext, OpNext, OpNext, OpNext, OpNext}, rec.ops)
+}
+
+// hookRecorder records the Op of each call, and passes the call on to Hooks.
+type hookRecorder struct {
+	Hooks
+	ops []Op
+}
+
+func (hr *hookRecorder) Begin(ctx context.Context, op Op) context.Context {
+	hr.ops = append(hr.ops, op)
+	return hr.Hooks.Begin(ctx, op)
+}
+
+func TestCheckpoint(t *testing.T) {
+	ctx := context.TODO()
+	marshalInt := func(out []byte, x int) []byte { return sbe.AppendUVarint(out, uint64(x)) }
+	unmarshalInt := func(data []byte, dst *int) error {
+		x, _, err := sbe.ReadUVarint(data)
+		*dst = int(x)
+		return err
+	}
+	// check reads k elements from each new Iterator, checkpoints, and resumes a fresh Iterator from the checkpoint.
+	check := func(t *testing.T, newIt func() Iterator[int]) {
+		expected, err := Collect(ctx, newIt(), 100)
+		require.NoError(t, err)
+		for k := 0; k <= len(expected); k++ {
+			it := newIt()
+			prefix, err := Collect(ctx, Take(it, k), 100)
+			require.NoError(t, err)
+			c, err := it.(Checkpointer[int]).Checkpoint(ctx)
+			require.NoError(t, err)
+			c, err = ParseCheckpoint(c.Marshal(nil, marshalInt), unmarshalInt)
+			require.NoError(t, err)
+
+			resumed, err := Resume(ctx, newIt(), c)
+			require.NoError(t, err)
+			rest, err := Collect(ctx, resumed, 100)
+			require.NoError(t, err)
+			require.Equal(t, expected, append(prefix, rest...), "k=%d", k)
+		}
+	}
+	t.Run("Slice", func(t *testing.T) {
+		check(t, func() Iterator[int] { return NewSlice([]int{5, 3, 1, 4}, nil) })
+	})
+	t.Run("Merger", func(t *testing.T) {
+		check(t, func() Iterator[int] {
+			return NewMerger([]Peekable[int]{
+				NewSortedSlice([]int{0, 2, 2, 4, 6}, cmp.Compare[int], nil),
+				NewSortedSlice([]int{1, 2, 3, 6}, cmp.Compare[int], nil),
+			}, cmp.Compare[int])
+		})
+	})
+	t.Run("MergerSeek", func(t *testing.T) {
+		newIt := func() *Merger[int] {
+			return NewMerger([]Peekable[int]{
+				NewSortedSlice([]int{0, 2, 4, 6}, cmp.Compare[int], nil),
+				NewSortedSlice([]int{1, 3, 5}, cmp.Compare[int], nil),
+			}, cmp.Compare[int])
+		}
+		m := newIt()
+		require.NoError(t, m.Seek(ctx, 4))
+		c, err := m.Checkpoint(ctx)
+		require.NoError(t, err)
+		resumed, err := Resume[int](ctx, newIt(), c)
+		require.NoError(t, err)
+		rest, err := Collect(ctx, resumed, 100)
+		require.NoError(t, err)
+		require.Equal(t, []int{4, 5, 6}, rest)
+	})
+	t.Run("NewCheckpoint", func(t *testing.T) {
+		xs := []int{1, 3, 3, 3, 5}
+		it, err := Resume[int](ctx, NewSortedSlice(xs, cmp.Compare[int], nil), NewCheckpoint(maybe.Just(3), 2))
+		require.NoError(t, err)
+		rest, err := Collect(ctx, it, 10)
+		require.NoError(t, err)
+		require.Equal(t, []int{3, 5}, rest)
+
+		it, err = Resume[int](ctx, NewSlice(xs, nil), EndCheckpoint[int]())
+		require.NoError(t, err)
+		_, err = Next(ctx, it)
+		require.ErrorIs(t, err, EOS())
+	})
+	t.Run("OJoiner", func(t *testing.T) {
+		newIt := func() Iterator[OJoined[int, int]] {
+			return NewOJoiner[int, int](
+				NewSortedSlice([]int{0, 2, 3, 5}, cmp.Compare[int], nil),
+				NewSortedSlice([]int{1, 2, 5, 7}, cmp.Compare[int], nil),
+				cmp.Compare[int],
+			)
+		}
+		expected, err := Collect(ctx, newIt(), 100)
+		require.NoError(t, err)
+		for k := 0; k <= len(expected); k++ {
+			it := newIt()
+			prefix, err := Collect(ctx, Take(it, k), 100)
+			require.NoError(t, err)
+			c, err := it.(Checkpointer[OJoined[int, int]]).Checkpoint(ctx)
+			require.NoError(t, err)
+			resumed, err := Resume(ctx, newIt(), c)
+			require.NoError(t, err)
+			rest, err := Collect(ctx, resumed, 100)
+			require.NoError(t, err)
+			require.Equal(t, zeroNothings(expected), zeroNothings(append(prefix, rest...)), "k=%d", k)
+		}
+	})
+}
+
+func TestPaginate(t *testing.T) {
+	ctx := context.TODO()
+	xs := make([]int, 25)
+	for i := range xs {
+		xs[i] = i
+	}
+	errFlaky := errors.New("flaky")
+	// fetch returns pages of 10 elements, and fails on the first attempt at each page.
+	var mu sync.Mutex
+	attempts := map[int]int{}
+	fetch := func(ctx context.Context, cursor int) ([]int, int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[cursor]++
+		if attempts[cursor] == 1 {
+			return nil, 0, errFlaky
+		}
+		end := min(cursor+10, len(xs))
+		if end == len(xs) {
+			return xs[cursor:end], end, EOS()
+		}
+		return xs[cursor:end], end, nil
+	}
+	retry := func(attempt int, err error) (time.Duration, bool) {
+		return 0, errors.Is(err, errFlaky) && attempt < 3
+	}
+
+	p := Paginate(ctx, 0, fetch, retry)
+	defer p.Close()
+	actual, err := Collect[int](ctx, p, 100)
+	require.NoError(t, err)
+	require.Equal(t, xs, actual)
+	mu.Lock()
+	require.Equal(t, map[int]int{0: 2, 10: 2, 20: 2}, attempts)
+	mu.Unlock()
+	_, err = p.Next(ctx, make([]int, 1))
+	require.ErrorIs(t, err, EOS())
+
+	// resume from the cursor
+	p = Paginate(ctx, 0, fetch, retry)
+	defer p.Close()
+	_, err = Collect(ctx, Take[int](p, 13), 100)
+	require.NoError(t, err)
+	cursor, offset := p.Cursor()
+	require.Equal(t, 10, cursor)
+	require.Equal(t, 3, offset)
+	p = Paginate(ctx, cursor, fetch, retry)
+	defer p.Close()
+	require.NoError(t, Skip[int](ctx, p, offset))
+	actual, err = Collect[int](ctx, p, 100)
+	require.NoError(t, err)
+	require.Equal(t, xs[13:], actual)
+
+	// resume after an error which is not retried
+	errPermanent := errors.New("permanent")
+	broken := func(ctx context.Context, cursor int) ([]int, int, error) {
+		if cursor == 20 {
+			return nil, 0, errPermanent
+		}
+		return fetch(ctx, cursor)
+	}
+	p = Paginate(ctx, 0, broken, retry)
+	defer p.Close()
+	actual, err = Collect[int](ctx, p, 100)
+	require.ErrorIs(t, err, errPermanent)
+	require.Equal(t, xs[:20], actual)
+	cursor, offset = p.Cursor()
+	require.Equal(t, 20, cursor)
+	require.Equal(t, 0, offset)
+	p = Paginate(ctx, cursor, fetch, retry)
+	defer p.Close()
+	actual, err = Collect[int](ctx, p, 100)
+	require.NoError(t, err)
+	require.Equal(t, xs[20:], actual)
+
+	// errors which are not retried are returned
+	require.NoError(t, p.Close())
+	mu.Lock()
+	clear(attempts)
+	mu.Unlock()
+	p = Paginate(ctx, 0, fetch, nil)
+	defer p.Close()
+	_, err = p.Next(ctx, make([]int, 1))
+	require.ErrorIs(t, err, errFlaky)
+}
+
+func TestFanIn(t *testing.T) {
+	ctx := context.TODO()
+	t.Run("Interleave", func(t *testing.T) {
+		slow := make(chan int)
+		f := FanIn(ctx, nil, Iterator[int](Chan[int](slow)), NewSlice([]int{0, 1, 2, 3}, nil), NewSlice([]int{10, 11}, nil))
+		defer f.Close()
+		// the slow input does not hold back the others
+		bySource := map[int][]int{}
+		for range 6 {
+			x, err := Next[Tagged[int]](ctx, f)
+			require.NoError(t, err)
+			bySource[x.Source] = append(bySource[x.Source], x.X)
+		}
+		require.Equal(t, map[int][]int{1: {0, 1, 2, 3}, 2: {10, 11}}, bySource)
+		slow <- 100
+		close(slow)
+		actual, err := Collect[Tagged[int]](ctx, f, 10)
+		require.NoError(t, err)
+		require.Equal(t, []Tagged[int]{{Source: 0, X: 100}}, actual)
+	})
+	errFail := errors.New("fail")
+	newInputs := func() []Iterator[int] {
+		return []Iterator[int]{
+			Concat(NewSlice([]int{0, 1}, nil), &failing[int]{err: errFail}),
+			NewSlice([]int{10, 11, 12}, nil),
+		}
+	}
+	t.Run("Propagate", func(t *testing.T) {
+		f := FanIn(ctx, nil, newInputs()...)
+		defer f.Close()
+		_, err := Collect[Tagged[int]](ctx, f, 10)
+		require.ErrorIs(t, err, errFail)
+		_, err = f.Next(ctx, make([]Tagged[int], 1))
+		require.ErrorIs(t, err, errFail)
+	})
+	t.Run("PropagateAfterArrived", func(t *testing.T) {
+		fast := make(chan int, 1)
+		fast <- 1
+		fail := make(chan int)
+		f := FanIn(ctx, nil, Iterator[int](Chan[int](fast)), Concat(Chan[int](fail), &failing[int]{err: errFail}))
+		defer f.Close()
+		// wait for the element from the fast input to arrive, and then fail the other input.
+		for len(fast) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		close(fail)
+		time.Sleep(10 * time.Millisecond)
+		actual, err := Collect[Tagged[int]](ctx, f, 100)
+		require.ErrorIs(t, err, errFail)
+		require.Equal(t, []Tagged[int]{{Source: 0, X: 1}}, actual)
+	})
+	t.Run("Isolate", func(t *testing.T) {
+		var failed []int
+		f := FanIn(ctx, func(source int, err error) error {
+			failed = append(failed, source)
+			return nil
+		}, newInputs()...)
+		defer f.Close()
+		actual, err := Collect[Tagged[int]](ctx, f, 10)
+		require.NoError(t, err)
+		require.Len(t, actual, 5)
+		require.Equal(t, []int{0}, failed)
+	})
+}
+
+// failing is an Iterator which always returns err
+type failing[T any] struct {
+	err error
+}
+
+func (it *failing[T]) Next(ctx context.Context, dst []T) (int, error) {
+	return 0, it.err
+}
+
+func TestResilient(t *testing.T) {
+	ctx := context.TODO()
+	xs := []int{0, 1, 1, 1, 2, 3, 3, 4, 5, 6, 7, 7, 8, 9}
+	errFlaky := errors.New("flaky")
+	retry := func(attempt int, err error) (time.Duration, bool) {
+		return 0, attempt < 3 && (errors.Is(err, errFlaky) || errors.Is(err, context.DeadlineExceeded))
+	}
+	t.Run("Retry", func(t *testing.T) {
+		var opened int
+		r := NewResilient(func(ctx context.Context) (Iterator[int], error) {
+			opened++
+			if opened == 2 {
+				return nil, errFlaky
+			}
+			// each Iterator fails after a few calls to Next
+			return &flaky[int]{SortedSlice: NewSortedSlice(xs, cmp.Compare[int], nil), n: 2, err: errFlaky}, nil
+		}, cmp.Compare[int], ResilientConfig{Retry: retry})
+		defer r.Close()
+		var actual []int
+		buf := make([]int, 2)
+		for {
+			n, err := r.Next(ctx, buf)
+			if IsEOS(err) {
+				break
+			}
+			require.NoError(t, err)
+			actual = append(actual, buf[:n]...)
+		}
+		require.Equal(t, xs, actual)
+		require.Greater(t, opened, 3)
+	})
+	t.Run("Timeout", func(t *testing.T) {
+		var opened int
+		r := NewResilient(func(ctx context.Context) (Iterator[int], error) {
+			opened++
+			it := NewSortedSlice(xs, cmp.Compare[int], nil)
+			if opened == 1 {
+				// the first Iterator hangs
+				return &flaky[int]{SortedSlice: it, n: 0, block: true}, nil
+			}
+			return it, nil
+		}, cmp.Compare[int], ResilientConfig{Timeout: 10 * time.Millisecond, Retry: retry})
+		defer r.Close()
+		actual, err := Collect[int](ctx, r, 100)
+		require.NoError(t, err)
+		require.Equal(t, xs, actual)
+		require.Equal(t, 2, opened)
+	})
+	t.Run("ContextBound", func(t *testing.T) {
+		// the Iterator stops working once the context it was opened with is done, like a network stream.
+		var opened []context.Context
+		r := NewResilient(func(ctx context.Context) (Iterator[int], error) {
+			opened = append(opened, ctx)
+			return &ctxBound[int]{SortedSlice: NewSortedSlice(xs, cmp.Compare[int], nil), ctx: ctx}, nil
+		}, cmp.Compare[int], ResilientConfig{Timeout: time.Second, Retry: retry})
+		var actual []int
+		buf := make([]int, 2)
+		for {
+			n, err := r.Next(ctx, buf)
+			if IsEOS(err) {
+				break
+			}
+			require.NoError(t, err)
+			actual = append(actual, buf[:n]...)
+		}
+		require.Equal(t, xs, actual)
+		require.Len(t, opened, 1)
+		require.NoError(t, r.Close())
+		require.Error(t, opened[0].Err())
+	})
+	t.Run("NoRetry", func(t *testing.T) {
+		r := NewResilient(func(ctx context.Context) (Iterator[int], error) {
+			return &flaky[int]{SortedSlice: NewSortedSlice(xs, cmp.Compare[int], nil), n: 1, err: errFlaky}, nil
+		}, cmp.Compare[int], ResilientConfig{})
+		defer r.Close()
+		_, err := Collect[int](ctx, r, 100)
+		require.ErrorIs(t, err, errFlaky)
+	})
+	t.Run("Misuse", func(t *testing.T) {
+		var opened int
+		always := func(int, error) (time.Duration, bool) { return 0, true }
+		r := NewResilient(func(ctx context.Context) (Iterator[int], error) {
+			opened++
+			return NewMap(Iterator[int](NewSlice(xs, nil)), func(y *int, x int) { *y = x }), nil
+		}, cmp.Compare[int], ResilientConfig{Retry: always})
+		defer r.Close()
+		require.ErrorContains(t, r.Seek(ctx, 3), "Seeker")
+		require.ErrorContains(t, r.Peek(ctx, new(int)), "Peekable")
+		require.Equal(t, 1, opened)
+	})
+}
+
+// ctxBound is a SortedSlice which fails once ctx is done.
+type ctxBound[T any] struct {
+	*SortedSlice[T]
+	ctx context.Context
+}
+
+func (it *ctxBound[T]) Next(ctx context.Context, dst []T) (int, error) {
+	if err := it.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return it.SortedSlice.Next(ctx, dst)
+}
+
+// flaky is a SortedSlice which fails after n calls to Next.
+// If block is true, then it blocks until the context is cancelled instead.
+type flaky[T any] struct {
+	*SortedSlice[T]
+	n     int
+	err   error
+	block bool
+}
+
+func (it *flaky[T]) Next(ctx context.Context, dst []T) (int, error) {
+	if it.n == 0 {
+		if it.block {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return 0, it.err
+	}
+	it.n--
+	return it.SortedSlice.Next(ctx, dst)
+}
+
 func TestOJoiner(t *testing.T) {
 	type testCase struct {
 		Left  []int
@@ -143,20 +1328,146 @@ func TestOJoiner(t *testing.T) {
 			j := NewOJoiner(l, r, cmp.Compare[int])
 			actual, err := Collect(ctx, j, len(tc.Left)+len(tc.Right))
 			require.NoError(t, err)
-			// to make equality checking easier, fully zero Nothings.
-			for i := range actual {
-				if !actual[i].Left.Ok {
-					actual[i].Left = maybe.Nothing[int]()
-				}
-				if !actual[i].Right.Ok {
-					actual[i].Right = maybe.Nothing[int]()
+			require.Equal(t, tc.Out, zeroNothings(actual))
+
+			// read again in batches
+			l.Reset()
+			r.Reset()
+			j = NewOJoiner(l, r, cmp.Compare[int])
+			actual = actual[:0]
+			buf := make([]OJoined[int, int], 3)
+			for {
+				n, err := j.Next(ctx, buf)
+				if IsEOS(err) {
+					break
 				}
+				require.NoError(t, err)
+				actual = append(actual, buf[:n]...)
 			}
+			require.Equal(t, tc.Out, zeroNothings(actual))
+		})
+	}
+}
+
+func TestNJoiner(t *testing.T) {
+	ctx := context.TODO()
+	newJoiner := func() *NJoiner[int] {
+		return NewNJoiner([]Peekable[int]{
+			NewSortedSlice([]int{1, 2, 4}, cmp.Compare[int], nil),
+			NewSlice([]int{2, 3, 4}, nil),
+			NewSortedSlice([]int{0, 4, 5}, cmp.Compare[int], nil),
+		}, cmp.Compare[int])
+	}
+	j, n := maybe.Just[int], maybe.Nothing[int]()
+	actual, err := Collect[[]maybe.Maybe[int]](ctx, newJoiner(), 10)
+	require.NoError(t, err)
+	require.Equal(t, [][]maybe.Maybe[int]{
+		{n, n, j(0)},
+		{j(1), n, n},
+		{j(2), j(2), n},
+		{n, j(3), n},
+		{j(4), j(4), j(4)},
+		{n, n, j(5)},
+	}, actual)
+
+	nj := newJoiner()
+	require.NoError(t, nj.Seek(ctx, []maybe.Maybe[int]{n, j(3)}))
+	actual, err = Collect[[]maybe.Maybe[int]](ctx, nj, 10)
+	require.NoError(t, err)
+	require.Equal(t, [][]maybe.Maybe[int]{
+		{n, j(3), n},
+		{j(4), j(4), j(4)},
+		{n, n, j(5)},
+	}, actual)
+}
+
+func TestOJoinerPartialBatch(t *testing.T) {
+	ctx := context.TODO()
+	t.Run("Error", func(t *testing.T) {
+		// the rows before an error are returned, and the error is returned by the next call.
+		errFail := errors.New("fail")
+		rit := NewPeeker[int](Concat(NewSlice([]int{1}, nil), &failing[int]{err: errFail}), nil)
+		j := NewOJoiner[int, int](NewSlice([]int{1, 2, 3}, nil), rit, cmp.Compare[int])
+		buf := make([]OJoined[int, int], 5)
+		n, err := j.Next(ctx, buf)
+		require.NoError(t, err)
+		require.Equal(t, []OJoined[int, int]{both(1)}, zeroNothings(buf[:n]))
+		_, err = j.Next(ctx, buf)
+		require.ErrorIs(t, err, errFail)
+	})
+	t.Run("Reset", func(t *testing.T) {
+		// rows emitted after one input has ended must not keep the other side from a reused buffer.
+		for _, tc := range []struct{ Left, Right []int }{
+			{Left: []int{1, 2, 3}, Right: []int{1}},
+			{Left: []int{1}, Right: []int{1, 2, 3}},
+		} {
+			j := NewOJoiner[int, int](NewSlice(tc.Left, nil), NewSlice(tc.Right, nil), cmp.Compare[int])
+			buf := []OJoined[int, int]{both(9), both(9), both(9)}
+			n, err := j.Next(ctx, buf[:1])
+			require.NoError(t, err)
+			require.Equal(t, []OJoined[int, int]{both(1)}, buf[:n])
+			n, err = j.Next(ctx, buf)
+			require.NoError(t, err)
+			for _, row := range buf[:n] {
+				require.False(t, row.Left.Ok && row.Right.Ok, "%v", row)
+			}
+			require.Equal(t, 2, n)
+		}
+	})
+}
+
+func TestIJoiner(t *testing.T) {
+	type testCase struct {
+		Left  []int
+		Right []int
+		Out   []IJoined[int, int]
+	}
+	tcs := []testCase{
+		{Left: nil, Right: nil,
+			Out: nil,
+		},
+		{Left: []int{1, 10}, Right: nil,
+			Out: nil,
+		},
+		{Left: []int{1, 2, 3}, Right: []int{2, 3, 4},
+			Out: []IJoined[int, int]{
+				{Left: 2, Right: 2},
+				{Left: 3, Right: 3},
+			},
+		},
+		{Left: []int{0, 2, 4, 6, 8}, Right: []int{1, 2, 3, 8, 9},
+			Out: []IJoined[int, int]{
+				{Left: 2, Right: 2},
+				{Left: 8, Right: 8},
+			},
+		},
+	}
+	for i, tc := range tcs {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			ctx := context.TODO()
+			l := NewSlice(tc.Left, nil)
+			r := NewSlice(tc.Right, nil)
+			j := NewIJoiner(l, r, cmp.Compare[int])
+			actual, err := Collect(ctx, j, len(tc.Left)+len(tc.Right))
+			require.NoError(t, err)
 			require.Equal(t, tc.Out, actual)
 		})
 	}
 }
 
+// zeroNothings fully zeros Nothings, to make equality checking easier.
+func zeroNothings[L, R any](xs []OJoined[L, R]) []OJoined[L, R] {
+	for i := range xs {
+		if !xs[i].Left.Ok {
+			xs[i].Left = maybe.Nothing[L]()
+		}
+		if !xs[i].Right.Ok {
+			xs[i].Right = maybe.Nothing[R]()
+		}
+	}
+	return xs
+}
+
 func leftOnly[T any](x T) OJoined[T, T] {
 	return OJoined[T, T]{Left: maybe.Just(x)}
 }
@@ -168,3 +1479,157 @@ func rightOnly[T any](x T) OJoined[T, T] {
 func both[T any](x T) OJoined[T, T] {
 	return OJoined[T, T]{Left: maybe.Just(x), Right: maybe.Just(x)}
 }
+
+func TestBatcher(t *testing.T) {
+	ctx := context.TODO()
+	t.Run("Deadline", func(t *testing.T) {
+		clk := newFakeClock()
+		c := make(chan int)
+		b := NewBatcherWithConfig(Chan[int](c), BatcherConfig{Min: 3, Delay: time.Second, Clock: clk})
+		defer b.Close()
+
+		type result struct {
+			batch []int
+			err   error
+		}
+		results := make(chan result)
+		next := func() {
+			batch, err := Next[[]int](ctx, b)
+			results <- result{batch, err}
+		}
+		go next()
+		c <- 1
+		clk.BlockUntil(1)
+		select {
+		case <-results:
+			t.Fatal("batch emitted before deadline")
+		default:
+		}
+		clk.Advance(time.Second)
+		require.Equal(t, result{batch: []int{1}}, <-results)
+
+		go next()
+		c <- 2
+		c <- 3
+		c <- 4
+		require.Equal(t, result{batch: []int{2, 3, 4}}, <-results)
+
+		close(c)
+		_, err := Next[[]int](ctx, b)
+		require.ErrorIs(t, err, EOS())
+	})
+	t.Run("Max", func(t *testing.T) {
+		xs := make([]int, 100)
+		for i := range xs {
+			xs[i] = i
+		}
+		b := NewBatcherWithConfig(NewSlice(xs, nil), BatcherConfig{Min: 2, Max: 4, Delay: time.Hour})
+		defer b.Close()
+		batches, err := Collect[[]int](ctx, b, len(xs))
+		require.NoError(t, err)
+		for _, batch := range batches[:len(batches)-1] {
+			require.GreaterOrEqual(t, len(batch), 2)
+			require.LessOrEqual(t, len(batch), 4)
+		}
+		require.Equal(t, xs, slices.Concat(batches...))
+	})
+	t.Run("TransientError", func(t *testing.T) {
+		errTransient := errors.New("transient")
+		inner := &errOnce[int]{Iterator: NewSlice([]int{0, 1, 2, 3}, nil), n: 2, err: errTransient}
+		b := NewBatcherWithConfig(inner, BatcherConfig{Min: 10, Delay: time.Hour})
+		defer b.Close()
+		// the partial batch is emitted before the error
+		batch, err := Next[[]int](ctx, b)
+		require.NoError(t, err)
+		require.Equal(t, []int{0, 1}, batch)
+		_, err = Next[[]int](ctx, b)
+		require.ErrorIs(t, err, errTransient)
+		// the Batcher can be retried
+		batch, err = Next[[]int](ctx, b)
+		require.NoError(t, err)
+		require.Equal(t, []int{2, 3}, batch)
+		_, err = Next[[]int](ctx, b)
+		require.ErrorIs(t, err, EOS())
+	})
+}
+
+// errOnce returns err once, after n elements have been read from Iterator.
+type errOnce[T any] struct {
+	Iterator[T]
+	n   int
+	err error
+}
+
+func (it *errOnce[T]) Next(ctx context.Context, dst []T) (int, error) {
+	if it.n == 0 && it.err != nil {
+		err := it.err
+		it.err = nil
+		return 0, err
+	}
+	if it.err != nil {
+		dst = dst[:min(len(dst), it.n)]
+	}
+	n, err := it.Iterator.Next(ctx, dst)
+	it.n -= n
+	return n, err
+}
+
+type fakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	c := &fakeClock{now: time.Unix(0, 0)}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, fakeTimer{at: c.now.Add(d), ch: ch})
+	c.cond.Broadcast()
+	return ch
+}
+
+// Advance moves the clock forward, firing any timers which are due.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.waiters = slices.DeleteFunc(c.waiters, func(w fakeTimer) bool {
+		if w.at.After(c.now) {
+			return false
+		}
+		w.ch <- c.now
+		return true
+	})
+}
+
+// BlockUntil blocks until there are n timers waiting.
+func (c *fakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
@@ -0,0 +1,150 @@
+// package streamstest checks that implementations of the streams interfaces satisfy their contracts.
+package streamstest
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.brendoncarroll.net/exp/streams"
+)
+
+// TestIterator calls Check on Iterators created by newIt, with many randomized sequences of operations.
+// It also checks that Iterators can be closed before they have been exhausted.
+// Each Iterator must emit exactly the elements in expected.
+// If cmp is not nil, then expected must be sorted by cmp, and Seek will be called on Iterators which implement Seeker.
+func TestIterator[T any](t *testing.T, expected []T, cmp func(a, b T) int, newIt func() streams.Iterator[T]) {
+	t.Run("NextUnit", func(t *testing.T) {
+		Check(t, expected, newIt(), cmp, nil)
+	})
+	t.Run("Random", func(t *testing.T) {
+		for i := range 100 {
+			rng := rand.New(rand.NewSource(int64(i)))
+			ops := make([]byte, rng.Intn(2*len(expected)+2))
+			rng.Read(ops)
+			Check(t, expected, newIt(), cmp, ops)
+		}
+	})
+	t.Run("CloseEarly", func(t *testing.T) {
+		for k := range min(len(expected), 3) + 1 {
+			it := newIt()
+			c := checker[T]{t: t, ctx: context.Background(), expected: expected, it: it, cmp: cmp}
+			for range k {
+				c.next(1)
+			}
+			require.NoError(t, streams.Close(it), "Close after %d elements", k)
+		}
+	})
+}
+
+// Check drives it with a sequence of operations decoded from ops, and checks the results against expected.
+// Each byte of ops selects an operation and its argument, so ops can be generated by a fuzzer.
+// Peek, Skip and Seek are only called on Iterators that implement Peekable, Skipper, and Seeker.
+// Seek is only called if cmp is not nil.
+// After the operations, the rest of it is read with Next, and it must return EOS forever after.
+// Finally, it is closed, and Close must not return an error.
+func Check[T any](t testing.TB, expected []T, it streams.Iterator[T], cmp func(a, b T) int, ops []byte) {
+	t.Helper()
+	ctx := context.Background()
+	c := checker[T]{t: t, ctx: ctx, expected: expected, it: it, cmp: cmp}
+	for _, op := range ops {
+		arg := int(op >> 2)
+		switch op % 4 {
+		case 0:
+			c.next(arg%8 + 1)
+		case 1:
+			c.peek()
+		case 2:
+			c.skip(arg % 4)
+		case 3:
+			c.seek(arg % 4)
+		}
+	}
+	for c.pos < len(expected) {
+		c.next(8)
+	}
+	for range 3 {
+		c.next(1)
+		c.next(8)
+	}
+	require.NoError(t, streams.Close(it))
+}
+
+type checker[T any] struct {
+	t        testing.TB
+	ctx      context.Context
+	expected []T
+	it       streams.Iterator[T]
+	cmp      func(a, b T) int
+
+	// pos is the index in expected of the next element.
+	pos int
+}
+
+func (c *checker[T]) next(size int) {
+	c.t.Helper()
+	buf := make([]T, size)
+	n, err := c.it.Next(c.ctx, buf)
+	if c.pos >= len(c.expected) {
+		require.True(c.t, streams.IsEOS(err), "expected EOS, got n=%d err=%v", n, err)
+		return
+	}
+	require.NoError(c.t, err)
+	require.Greater(c.t, n, 0, "Next returned n == 0 without an error")
+	require.LessOrEqual(c.t, n, len(buf))
+	require.LessOrEqual(c.t, c.pos+n, len(c.expected), "Next emitted too many elements")
+	require.Equal(c.t, c.expected[c.pos:c.pos+n], buf[:n])
+	c.pos += n
+}
+
+func (c *checker[T]) peek() {
+	c.t.Helper()
+	p, ok := c.it.(streams.Peekable[T])
+	if !ok {
+		c.next(1)
+		return
+	}
+	var x T
+	err := p.Peek(c.ctx, &x)
+	if c.pos >= len(c.expected) {
+		require.True(c.t, streams.IsEOS(err), "expected EOS, got %v", err)
+		return
+	}
+	require.NoError(c.t, err)
+	require.Equal(c.t, c.expected[c.pos], x)
+}
+
+func (c *checker[T]) skip(n int) {
+	c.t.Helper()
+	if _, ok := c.it.(streams.Skipper); !ok {
+		c.next(n + 1)
+		return
+	}
+	err := streams.Skip(c.ctx, c.it, n)
+	if c.pos+n > len(c.expected) {
+		require.True(c.t, streams.IsEOS(err), "expected EOS, got %v", err)
+		c.pos = len(c.expected)
+		return
+	}
+	require.NoError(c.t, err)
+	c.pos += n
+}
+
+func (c *checker[T]) seek(ahead int) {
+	c.t.Helper()
+	sk, ok := c.it.(streams.Seeker[T])
+	if !ok || c.cmp == nil || c.pos+ahead >= len(c.expected) {
+		c.next(ahead + 1)
+		return
+	}
+	gteq := c.expected[c.pos+ahead]
+	err := sk.Seek(c.ctx, gteq)
+	for c.pos < len(c.expected) && c.cmp(c.expected[c.pos], gteq) < 0 {
+		c.pos++
+	}
+	if err != nil && c.pos >= len(c.expected) && streams.IsEOS(err) {
+		return
+	}
+	require.NoError(c.t, err)
+}
@@ -0,0 +1,137 @@
+package streamstest
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"testing"
+
+	"go.brendoncarroll.net/exp/streams"
+)
+
+func TestStreams(t *testing.T) {
+	ctx := context.Background()
+	xs := make([]int, 20)
+	for i := range xs {
+		xs[i] = i * 2
+	}
+	type testCase struct {
+		Name  string
+		NewIt func() streams.Iterator[int]
+	}
+	tcs := []testCase{
+		{Name: "Slice", NewIt: func() streams.Iterator[int] {
+			return streams.NewSlice(xs, nil)
+		}},
+		{Name: "SortedSlice", NewIt: func() streams.Iterator[int] {
+			return streams.NewSortedSlice(xs, cmp.Compare[int], nil)
+		}},
+		{Name: "Seq", NewIt: func() streams.Iterator[int] {
+			return streams.NewSeq(slices.Values(xs))
+		}},
+		{Name: "Peeker", NewIt: func() streams.Iterator[int] {
+			return streams.NewPeeker[int](streams.NewSeq(slices.Values(xs)), nil)
+		}},
+		{Name: "Concat", NewIt: func() streams.Iterator[int] {
+			return streams.Concat[int](
+				streams.NewSlice(xs[:5], nil),
+				streams.NewSlice[int](nil, nil),
+				streams.NewSlice(xs[5:], nil),
+			)
+		}},
+		{Name: "FlatMap", NewIt: func() streams.Iterator[int] {
+			// each outer element is the start of a chunk of up to 3 elements of xs
+			return streams.FlatMap(streams.NewSlice([]int{0, 3, 6, 9, 12, 15, 18}, nil), func(ctx context.Context, i int) (streams.Iterator[int], error) {
+				return streams.NewSlice(xs[i:min(i+3, len(xs))], nil), nil
+			})
+		}},
+		{Name: "Merger", NewIt: func() streams.Iterator[int] {
+			var evens, odds []int
+			for i, x := range xs {
+				if i%2 == 0 {
+					evens = append(evens, x)
+				} else {
+					odds = append(odds, x)
+				}
+			}
+			return streams.NewMerger([]streams.Peekable[int]{
+				streams.NewSortedSlice(evens, cmp.Compare[int], nil),
+				streams.NewSlice(odds, nil),
+			}, cmp.Compare[int])
+		}},
+		{Name: "Layered", NewIt: func() streams.Iterator[int] {
+			// the newer layer shadows the older one, and -1 is a tombstone.
+			return streams.NewLayered([]streams.Peekable[int]{
+				streams.NewSortedSlice([]int{-1, 0, 10, 20}, cmp.Compare[int], nil),
+				streams.NewSortedSlice(xs, cmp.Compare[int], nil),
+			}, cmp.Compare[int], func(x int) bool { return x < 0 })
+		}},
+		{Name: "Filter", NewIt: func() streams.Iterator[int] {
+			return streams.NewFilter[int](streams.NewSortedSlice(xs, cmp.Compare[int], nil), func(int) bool { return true })
+		}},
+		{Name: "Mutator", NewIt: func() streams.Iterator[int] {
+			return streams.NewSeekMutator[int](streams.NewSortedSlice(xs, cmp.Compare[int], nil), func(*int) bool { return true })
+		}},
+		{Name: "Map", NewIt: func() streams.Iterator[int] {
+			return streams.NewMap[int](streams.NewSlice(xs, nil), func(y *int, x int) { *y = x })
+		}},
+		{Name: "Prefetch", NewIt: func() streams.Iterator[int] {
+			return streams.Prefetch[int](ctx, streams.NewSlice(xs, nil), 4)
+		}},
+		{Name: "ParallelMap", NewIt: func() streams.Iterator[int] {
+			return streams.NewParallelMap(ctx, streams.NewSlice(xs, nil), 4, func(ctx context.Context, y *int, x int) error {
+				*y = x
+				return nil
+			})
+		}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.Name, func(t *testing.T) {
+			TestIterator(t, xs, cmp.Compare[int], tc.NewIt)
+		})
+	}
+}
+
+func TestTake(t *testing.T) {
+	xs := make([]int, 40)
+	for i := range xs {
+		xs[i] = i
+	}
+	newSorted := func() streams.Iterator[int] {
+		return streams.NewSortedSlice(xs, cmp.Compare[int], nil)
+	}
+	type testCase struct {
+		Name     string
+		Expected []int
+		// Cmp is nil for operators where Seek does not preserve the position based semantics.
+		Cmp   func(a, b int) int
+		NewIt func() streams.Iterator[int]
+	}
+	tcs := []testCase{
+		{Name: "Take", Expected: xs[:10], NewIt: func() streams.Iterator[int] {
+			return streams.Take(newSorted(), 10)
+		}},
+		{Name: "TakeWhile", Expected: xs[:15], Cmp: cmp.Compare[int], NewIt: func() streams.Iterator[int] {
+			return streams.TakeWhile(newSorted(), func(x int) bool { return x < 15 })
+		}},
+		{Name: "SkipWhile", Expected: xs[15:], Cmp: cmp.Compare[int], NewIt: func() streams.Iterator[int] {
+			return streams.SkipWhile(newSorted(), func(x int) bool { return x < 15 })
+		}},
+		{Name: "StepBy", Expected: []int{0, 3, 6, 9, 12, 15, 18, 21, 24, 27, 30, 33, 36, 39}, NewIt: func() streams.Iterator[int] {
+			return streams.StepBy(streams.NewSlice(xs, nil), 3)
+		}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.Name, func(t *testing.T) {
+			TestIterator(t, tc.Expected, tc.Cmp, tc.NewIt)
+		})
+	}
+}
+
+func FuzzSortedSlice(f *testing.F) {
+	xs := []int{0, 1, 1, 2, 3, 5, 8, 13, 21}
+	f.Add([]byte{0, 1, 2, 3})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		Check(t, xs, streams.NewSortedSlice(xs, cmp.Compare[int], nil), cmp.Compare[int], ops)
+	})
+}
@@ -0,0 +1,303 @@
+package streams
+
+import (
+	"context"
+	"fmt"
+)
+
+// Take returns an Iterator which emits at most n elements from it.
+// Elements which are passed over by Seek do not count towards n, so Seek followed by Take
+// can be used to read a page of results starting at a key.
+// The returned Iterator implements Peekable and Seeker if it does.
+func Take[T any](it Iterator[T], n int) Iterator[T] {
+	return wrapOp[T](&take[T]{inner: it, n: n}, isPeekable(it), isSeeker(it))
+}
+
+// TakeWhile returns an Iterator which emits elements from it, until pred returns false.
+// The returned Iterator implements Peekable and Seeker if it does.
+func TakeWhile[T any](it Iterator[T], pred func(T) bool) Iterator[T] {
+	return wrapOp[T](&takeWhile[T]{inner: it, pred: pred}, isPeekable(it), isSeeker(it))
+}
+
+// SkipWhile returns an Iterator which drops the leading elements from it for which pred returns true,
+// and then emits the rest of the elements.
+// Elements are dropped with Skip, which uses Skipper if it is implemented.
+// The returned Iterator implements Peekable, and implements Seeker if it is Peekable and a Seeker.
+func SkipWhile[T any](it Iterator[T], pred func(T) bool) Iterator[T] {
+	return wrapOp[T](&skipWhile[T]{inner: NewPeeker(it, nil), pred: pred, skipping: true}, true, isPeekable(it) && isSeeker(it))
+}
+
+// StepBy returns an Iterator which emits the first element from it, and then every kth element after that.
+// Elements in between are dropped with Skip, which uses Skipper if it is implemented.
+// After a call to Seek, the first element >= gteq is emitted, and the step starts over from there.
+// The returned Iterator implements Peekable and Seeker if it does.
+func StepBy[T any](it Iterator[T], k int) Iterator[T] {
+	if k < 1 {
+		panic(fmt.Sprintf("streams.StepBy: k must be positive: %d", k))
+	}
+	return wrapOp[T](&stepBy[T]{inner: it, k: k}, isPeekable(it), isSeeker(it))
+}
+
+type take[T any] struct {
+	inner Iterator[T]
+	n     int
+}
+
+func (t *take[T]) Next(ctx context.Context, dst []T) (int, error) {
+	if t.n <= 0 {
+		return 0, EOS()
+	}
+	if len(dst) > t.n {
+		dst = dst[:t.n]
+	}
+	n, err := t.inner.Next(ctx, dst)
+	if err != nil {
+		return 0, err
+	}
+	t.n -= n
+	return n, nil
+}
+
+func (t *take[T]) peek(ctx context.Context, dst *T) error {
+	if t.n <= 0 {
+		return EOS()
+	}
+	return t.inner.(Peekable[T]).Peek(ctx, dst)
+}
+
+func (t *take[T]) seek(ctx context.Context, gteq T) error {
+	return t.inner.(Seeker[T]).Seek(ctx, gteq)
+}
+
+func (t *take[T]) Close() error {
+	return Close(t.inner)
+}
+
+type takeWhile[T any] struct {
+	inner Iterator[T]
+	pred  func(T) bool
+	done  bool
+}
+
+func (t *takeWhile[T]) Next(ctx context.Context, dst []T) (int, error) {
+	if t.done {
+		return 0, EOS()
+	}
+	n, err := t.inner.Next(ctx, dst)
+	if err != nil {
+		return 0, err
+	}
+	for i := range dst[:n] {
+		if !t.pred(dst[i]) {
+			t.done = true
+			if i == 0 {
+				return 0, EOS()
+			}
+			return i, nil
+		}
+	}
+	return n, nil
+}
+
+func (t *takeWhile[T]) peek(ctx context.Context, dst *T) error {
+	if t.done {
+		return EOS()
+	}
+	if err := t.inner.(Peekable[T]).Peek(ctx, dst); err != nil {
+		return err
+	}
+	if !t.pred(*dst) {
+		// the element will never be emitted, so the stream is over.
+		t.done = true
+		return EOS()
+	}
+	return nil
+}
+
+func (t *takeWhile[T]) seek(ctx context.Context, gteq T) error {
+	if t.done {
+		return nil
+	}
+	return t.inner.(Seeker[T]).Seek(ctx, gteq)
+}
+
+func (t *takeWhile[T]) Close() error {
+	return Close(t.inner)
+}
+
+type skipWhile[T any] struct {
+	inner    Peekable[T]
+	pred     func(T) bool
+	skipping bool
+}
+
+// skip drops elements until pred returns false.
+func (s *skipWhile[T]) skip(ctx context.Context) error {
+	var x T
+	for s.skipping {
+		if err := s.inner.Peek(ctx, &x); err != nil {
+			return err
+		}
+		if !s.pred(x) {
+			s.skipping = false
+			break
+		}
+		if err := Skip[T](ctx, s.inner, 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *skipWhile[T]) Next(ctx context.Context, dst []T) (int, error) {
+	if err := s.skip(ctx); err != nil {
+		return 0, err
+	}
+	return s.inner.Next(ctx, dst)
+}
+
+func (s *skipWhile[T]) peek(ctx context.Context, dst *T) error {
+	if err := s.skip(ctx); err != nil {
+		return err
+	}
+	return s.inner.Peek(ctx, dst)
+}
+
+func (s *skipWhile[T]) seek(ctx context.Context, gteq T) error {
+	return s.inner.(Seeker[T]).Seek(ctx, gteq)
+}
+
+func (s *skipWhile[T]) Close() error {
+	return Close[T](s.inner)
+}
+
+type stepBy[T any] struct {
+	inner Iterator[T]
+	k     int
+	// pending is the number of elements to skip before the next element is emitted.
+	pending int
+}
+
+func (s *stepBy[T]) skip(ctx context.Context) error {
+	if s.pending > 0 {
+		if err := Skip(ctx, s.inner, s.pending); err != nil {
+			return err
+		}
+		s.pending = 0
+	}
+	return nil
+}
+
+func (s *stepBy[T]) Next(ctx context.Context, dst []T) (int, error) {
+	var n int
+	for n < len(dst) {
+		if err := s.skip(ctx); err != nil {
+			if n > 0 {
+				break
+			}
+			return 0, err
+		}
+		if err := NextUnit(ctx, s.inner, &dst[n]); err != nil {
+			if n > 0 {
+				break
+			}
+			return 0, err
+		}
+		s.pending = s.k - 1
+		n++
+	}
+	return n, nil
+}
+
+func (s *stepBy[T]) peek(ctx context.Context, dst *T) error {
+	if err := s.skip(ctx); err != nil {
+		return err
+	}
+	return s.inner.(Peekable[T]).Peek(ctx, dst)
+}
+
+func (s *stepBy[T]) seek(ctx context.Context, gteq T) error {
+	if err := s.inner.(Seeker[T]).Seek(ctx, gteq); err != nil {
+		return err
+	}
+	s.pending = 0
+	return nil
+}
+
+func (s *stepBy[T]) Close() error {
+	return Close(s.inner)
+}
+
+// opCore is implemented by combinators which can support Peek and Seek,
+// but only if their input does.
+// wrapOp exposes the methods which are supported.
+type opCore[T any] interface {
+	Iterator[T]
+	Closer
+	peek(ctx context.Context, dst *T) error
+	seek(ctx context.Context, gteq T) error
+}
+
+func wrapOp[T any](c opCore[T], peekable, seekable bool) Iterator[T] {
+	o := op[T]{c: c}
+	switch {
+	case peekable && seekable:
+		return &peekSeekOp[T]{o}
+	case peekable:
+		return &peekOp[T]{o}
+	case seekable:
+		return &seekOp[T]{o}
+	default:
+		return &o
+	}
+}
+
+func isPeekable[T any](it Iterator[T]) bool {
+	_, ok := it.(Peekable[T])
+	return ok
+}
+
+func isSeeker[T any](it Iterator[T]) bool {
+	_, ok := it.(Seeker[T])
+	return ok
+}
+
+type op[T any] struct {
+	c opCore[T]
+}
+
+func (o *op[T]) Next(ctx context.Context, dst []T) (int, error) {
+	return o.c.Next(ctx, dst)
+}
+
+func (o *op[T]) Close() error {
+	return o.c.Close()
+}
+
+type peekOp[T any] struct {
+	op[T]
+}
+
+func (o *peekOp[T]) Peek(ctx context.Context, dst *T) error {
+	return o.c.peek(ctx, dst)
+}
+
+type seekOp[T any] struct {
+	op[T]
+}
+
+func (o *seekOp[T]) Seek(ctx context.Context, gteq T) error {
+	return o.c.seek(ctx, gteq)
+}
+
+type peekSeekOp[T any] struct {
+	op[T]
+}
+
+func (o *peekSeekOp[T]) Peek(ctx context.Context, dst *T) error {
+	return o.c.peek(ctx, dst)
+}
+
+func (o *peekSeekOp[T]) Seek(ctx context.Context, gteq T) error {
+	return o.c.seek(ctx, gteq)
+}
@@ -0,0 +1,183 @@
+package streams
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+var _ Iterator[int] = &TeeBranch[int]{}
+
+// Tee splits src into n Iterators, which each emit every element of src.
+// The branches can be advanced independently, but the fastest branch will block once it is max elements
+// ahead of the slowest branch, so the branches should be consumed on separate goroutines.
+// A branch which will not be read any further should be closed, so that it does not hold back the others.
+//
+// cp is used to copy elements from the shared buffer into each branch. If it is nil, then assignment is used.
+func Tee[T any](src Iterator[T], n int, max int, cp func(dst *T, src T)) []*TeeBranch[T] {
+	if max < 1 {
+		max = 1
+	}
+	if cp == nil {
+		cp = func(dst *T, src T) { *dst = src }
+	}
+	t := &tee[T]{
+		src:     src,
+		cp:      cp,
+		max:     max,
+		pos:     make([]uint64, n),
+		closed:  make([]bool, n),
+		changed: make(chan struct{}),
+	}
+	branches := make([]*TeeBranch[T], n)
+	for i := range branches {
+		branches[i] = &TeeBranch[T]{t: t, i: i}
+	}
+	return branches
+}
+
+// TeeBranch is one of the Iterators returned by Tee
+type TeeBranch[T any] struct {
+	t *tee[T]
+	i int
+}
+
+func (b *TeeBranch[T]) Next(ctx context.Context, dst []T) (int, error) {
+	return b.t.next(ctx, b.i, dst)
+}
+
+// Close detaches the branch from the source, so that the other branches will not wait for it.
+// The source is closed when all of the branches have been closed.
+func (b *TeeBranch[T]) Close() error {
+	return b.t.close(b.i)
+}
+
+type tee[T any] struct {
+	src Iterator[T]
+	cp  func(dst *T, src T)
+	max int
+
+	mu sync.Mutex
+	// buf[off:] holds the elements from base to the end of what has been read from src.
+	buf  []T
+	off  int
+	base uint64
+	// pos is the position of each branch in the stream.
+	pos    []uint64
+	closed []bool
+	// err is the error returned by src, after the last element in buf
+	err error
+	// reading is true while a branch is reading from src
+	reading bool
+	// changed is closed and replaced whenever the state changes
+	changed chan struct{}
+}
+
+func (t *tee[T]) next(ctx context.Context, i int, dst []T) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed[i] {
+		return 0, fmt.Errorf("streams: Next called on closed TeeBranch")
+	}
+	for {
+		live := t.buf[t.off:]
+		end := t.base + uint64(len(live))
+		if p := t.pos[i]; p < end {
+			n := min(len(dst), int(end-p))
+			for j := range n {
+				t.cp(&dst[j], live[int(p-t.base)+j])
+			}
+			t.pos[i] += uint64(n)
+			t.trim()
+			return n, nil
+		}
+		if t.err != nil {
+			return 0, t.err
+		}
+		if t.reading || len(live) >= t.max {
+			// wait for another branch to read from src, or for the slowest branch to catch up.
+			changed := t.changed
+			t.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				t.mu.Lock()
+				return 0, ctx.Err()
+			case <-changed:
+			}
+			t.mu.Lock()
+			continue
+		}
+		if err := t.read(ctx, min(len(dst), t.max-len(live))); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// read reads up to n elements from src into buf.
+// The lock is released while src is being read.
+// read only returns context errors, other errors are stored in t.err.
+func (t *tee[T]) read(ctx context.Context, n int) error {
+	t.reading = true
+	tmp := make([]T, n)
+	t.mu.Unlock()
+	n, err := t.src.Next(ctx, tmp)
+	t.mu.Lock()
+	t.reading = false
+	defer t.broadcast()
+	if err != nil {
+		if ctx.Err() != nil {
+			// don't end the stream for the other branches because of this caller's context
+			return err
+		}
+		t.err = err
+		return nil
+	}
+	if t.off > 0 && t.off >= len(t.buf)/2 {
+		m := copy(t.buf, t.buf[t.off:])
+		clear(t.buf[m:])
+		t.buf = t.buf[:m]
+		t.off = 0
+	}
+	t.buf = append(t.buf, tmp[:n]...)
+	return nil
+}
+
+// trim drops elements which every open branch has read.
+func (t *tee[T]) trim() {
+	lowest := t.base + uint64(len(t.buf)-t.off)
+	for i, p := range t.pos {
+		if !t.closed[i] {
+			lowest = min(lowest, p)
+		}
+	}
+	if k := int(lowest - t.base); k > 0 {
+		clear(t.buf[t.off : t.off+k])
+		t.off += k
+		t.base = lowest
+		t.broadcast()
+	}
+}
+
+func (t *tee[T]) close(i int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed[i] {
+		return nil
+	}
+	t.closed[i] = true
+	t.trim()
+	for _, closed := range t.closed {
+		if !closed {
+			return nil
+		}
+	}
+	return Close(t.src)
+}
+
+func (t *tee[T]) broadcast() {
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
@@ -0,0 +1,171 @@
+package streams
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+var (
+	_ Iterator[[]int] = &Sliding[int]{}
+	_ Iterator[[]int] = &Splitter[int]{}
+)
+
+// Sliding emits windows of size elements, with the start of each window step elements after the last.
+// If step < size the windows overlap, and if step > size some elements are not in any window.
+// The last window will be shorter than size if the stream ends, but only if it contains elements
+// which have not been emitted before.
+type Sliding[T any] struct {
+	inner Iterator[T]
+	size  int
+	step  int
+
+	// buf holds the current window
+	buf []T
+	// emitted is true if buf has been emitted
+	emitted bool
+	// fresh is the number of elements in buf which have not been emitted
+	fresh int
+	// gap is the number of elements which still need to be skipped before the next window
+	gap int
+	err error
+}
+
+// NewSliding creates a new Sliding window Iterator.
+func NewSliding[T any](inner Iterator[T], size, step int) *Sliding[T] {
+	if size < 1 || step < 1 {
+		panic(fmt.Sprintf("streams: invalid window size=%d step=%d", size, step))
+	}
+	return &Sliding[T]{
+		inner: inner,
+		size:  size,
+		step:  step,
+	}
+}
+
+// NewTumbling creates a Sliding window Iterator which emits consecutive, non-overlapping windows of size elements.
+func NewTumbling[T any](inner Iterator[T], size int) *Sliding[T] {
+	return NewSliding(inner, size, size)
+}
+
+func (s *Sliding[T]) Next(ctx context.Context, dst [][]T) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	if s.err != nil {
+		return 0, s.err
+	}
+	if s.emitted {
+		// advance to the start of the next window
+		if s.step <= len(s.buf) {
+			n := copy(s.buf, s.buf[s.step:])
+			clear(s.buf[n:])
+			s.buf = s.buf[:n]
+		} else {
+			s.gap = s.step - len(s.buf)
+			clear(s.buf)
+			s.buf = s.buf[:0]
+		}
+		s.emitted = false
+		s.fresh = 0
+	}
+	// skip one element at a time, so that the gap is not skipped again after an error.
+	for s.gap > 0 {
+		if err := Skip(ctx, s.inner, 1); err != nil {
+			if IsEOS(err) {
+				s.err = err
+			}
+			return 0, err
+		}
+		s.gap--
+	}
+	for len(s.buf) < s.size {
+		var x T
+		if err := NextUnit(ctx, s.inner, &x); err != nil {
+			if !IsEOS(err) {
+				return 0, err
+			}
+			s.err = err
+			if s.fresh == 0 {
+				return 0, err
+			}
+			break
+		}
+		s.buf = append(s.buf, x)
+		s.fresh++
+	}
+	dst[0] = append(dst[0][:0], s.buf...)
+	s.emitted = true
+	return 1, nil
+}
+
+// Close implements Closer
+func (s *Sliding[T]) Close() error {
+	return Close(s.inner)
+}
+
+// Splitter emits runs of consecutive elements as windows.
+// A new window is started whenever split returns true for adjacent elements.
+type Splitter[T any] struct {
+	inner Iterator[T]
+	split func(prev, next T) bool
+
+	// buf holds the current window
+	buf []T
+}
+
+// NewSplitter creates a new Splitter.
+func NewSplitter[T any](inner Iterator[T], split func(prev, next T) bool) *Splitter[T] {
+	return &Splitter[T]{
+		inner: inner,
+		split: split,
+	}
+}
+
+// NewKeyWindows creates a Splitter which emits runs of elements with the same key.
+// To compute fixed windows of time, use a key which truncates the timestamp.
+func NewKeyWindows[T any, K comparable](inner Iterator[T], key func(T) K) *Splitter[T] {
+	return NewSplitter(inner, func(prev, next T) bool {
+		return key(prev) != key(next)
+	})
+}
+
+// NewSessionWindows creates a Splitter which starts a new window when more than gap
+// has passed between the timestamps of adjacent elements.
+// The elements are assumed to be sorted by timestamp.
+func NewSessionWindows[T any](inner Iterator[T], timestamp func(T) time.Time, gap time.Duration) *Splitter[T] {
+	return NewSplitter(inner, func(prev, next T) bool {
+		return timestamp(next).Sub(timestamp(prev)) > gap
+	})
+}
+
+func (s *Splitter[T]) Next(ctx context.Context, dst [][]T) (int, error) {
+	if len(dst) == 0 {
+		return 0, nil
+	}
+	for {
+		var x T
+		if err := NextUnit(ctx, s.inner, &x); err != nil {
+			if IsEOS(err) && len(s.buf) > 0 {
+				break
+			}
+			return 0, err
+		}
+		if len(s.buf) > 0 && s.split(s.buf[len(s.buf)-1], x) {
+			dst[0] = append(dst[0][:0], s.buf...)
+			clear(s.buf)
+			s.buf = append(s.buf[:0], x)
+			return 1, nil
+		}
+		s.buf = append(s.buf, x)
+	}
+	dst[0] = append(dst[0][:0], s.buf...)
+	clear(s.buf)
+	s.buf = s.buf[:0]
+	return 1, nil
+}
+
+// Close implements Closer
+func (s *Splitter[T]) Close() error {
+	return Close(s.inner)
+}